@@ -1,9 +1,13 @@
 package qiskit_api_go
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
+	"context"
+	"sort"
+	"strconv"
+	"golang.org/x/sync/errgroup"
 )
 
 // OldBackends is a map of all the recognized old backend names
@@ -11,7 +15,7 @@ var OldBackendNames = map[string]string{
 	"ibmqx5qv2": "real",
 	"ibmqx2": "real",
 	"qx5qv2": "real",
-	"qx5q": "rea;",
+	"qx5q": "real",
 	"real": "real",
 	"ibmqx3": "ibmqx3",
 	"simulator": "sim_trivial_2",
@@ -24,7 +28,7 @@ type Backend struct {
 	SerialNum	string	`json:"serialNumber,omitempty"`
 	Id 			string	`json:"id,omitempty"`
 	TopologyId	string	`json:"topologyId,omitempty"`
-	CouplingMap	interface{}	`json:"couplingMap,omitempty"`	// Note: this is either 'all-to-all' or [][]int
+	CouplingMap	CouplingMap	`json:"couplingMap,omitempty"`
 	Name 		string	`json:"name,omitempty"`
 	Status		string	`json:"status,omitempty"`
 	Description	string	`json:"description,omitempty"`
@@ -37,6 +41,85 @@ type Backend struct {
 	BasisGates	string	`json:"basisGates,omitempty"`
 }
 
+// String returns a summary of the backend, e.g. "ibmqx4 (5 qubits, real, on)"
+func (b Backend) String() string {
+	kind := "real"
+	if b.Simulator {
+		kind = "simulator"
+	}
+	return b.Name + " (" + strconv.FormatInt(b.Nqubits, 10) + " qubits, " + kind + ", " + b.Status + ")"
+}
+
+// CouplingMap represents the qubit connectivity of a backend
+// The API returns either the string "all-to-all" or a list of edges, where an edge is
+// either a [control, target] pair or a [control, [targets...]] node with its neighbors
+type CouplingMap struct {
+	allToAll bool
+	edges [][2]int
+}
+
+// IsAllToAll reports whether every qubit is coupled to every other qubit, in which case there are no Edges
+func (cm CouplingMap) IsAllToAll() bool {
+	return cm.allToAll
+}
+
+// Edges returns the coupling map as a flat list of [control, target] edges
+func (cm CouplingMap) Edges() [][2]int {
+	return cm.edges
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (cm CouplingMap) MarshalJSON() ([]byte, error) {
+	if cm.allToAll {
+		return json.Marshal("all-to-all")
+	}
+	return json.Marshal(cm.edges)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (cm *CouplingMap) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		if str != "all-to-all" {
+			return fmt.Errorf("unknown coupling map: %s", str)
+		}
+		*cm = CouplingMap{allToAll: true}
+		return nil
+	}
+
+	var entries [][]json.RawMessage
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+
+	*cm = CouplingMap{}
+	for _, entry := range entries {
+		if len(entry) != 2 {
+			return fmt.Errorf("invalid coupling map entry of length %d", len(entry))
+		}
+
+		var control int
+		if err := json.Unmarshal(entry[0], &control); err != nil {
+			return err
+		}
+
+		var target int
+		if err := json.Unmarshal(entry[1], &target); err == nil {
+			cm.edges = append(cm.edges, [2]int{control, target})
+			continue
+		}
+
+		var targets []int
+		if err := json.Unmarshal(entry[1], &targets); err != nil {
+			return err
+		}
+		for _, target := range targets {
+			cm.edges = append(cm.edges, [2]int{control, target})
+		}
+	}
+	return nil
+}
+
 // Backends is an alias for a map of backend name to Backend data structure
 type Backends map[string]*Backend
 
@@ -50,41 +133,192 @@ func (bs Backends) Sims() (simBs []*Backend) {
 	return simBs
 }
 
+// filter returns the backends for which keep returns true
+func (bs Backends) filter(keep func(*Backend) bool) Backends {
+	filtered := make(Backends)
+	for name, b := range bs {
+		if keep(b) {
+			filtered[name] = b
+		}
+	}
+	return filtered
+}
+
+// Reals returns the real devices out of this set of backends
+func (bs Backends) Reals() Backends {
+	return bs.filter(func(b *Backend) bool { return !b.Simulator })
+}
+
+// WithMinQubits returns the backends out of this set of backends which have at least n qubits
+func (bs Backends) WithMinQubits(n int64) Backends {
+	return bs.filter(func(b *Backend) bool { return b.Nqubits >= n })
+}
+
+// Online returns the backends out of this set of backends which are on
+func (bs Backends) Online() Backends {
+	return bs.filter(func(b *Backend) bool { return b.Status == "on" })
+}
+
 // AvailableBackends returns all the available backends that can be used
+// The known backends are replaced by the available ones, so backends which went offline are no longer accepted.
 // If options is used it must be of length three and appear in this order: hub, group, project
-func (c *Client) AvailableBackends(options ...ClientOption) Backends {
-	for _, option := range options {
-		option(c.opts)
+func (c *Client) AvailableBackends(options ...ClientOption) (Backends, error) {
+	opts := c.callOptions(options...)
+
+	i, err := c.fetchBackends(context.Background(), backendsUrl(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(Backends, len(i))
+	for _, b := range i {
+		if b.Status == "on" {
+			available[b.Name] = b
+		}
+	}
+
+	c.SetBackendCache(available)
+	return available, nil
+}
+
+// Backend returns the known backend with the given name, refreshing the known backends with AvailableBackends if it is unknown
+// A BadBackendErr listing the available backends is returned if the backend is still unknown after refreshing.
+func (c *Client) Backend(name string) (*Backend, error) {
+	if b := c.cachedBackend(name); b != nil {
+		return b, nil
+	}
+
+	bs, err := c.AvailableBackends()
+	if err != nil {
+		return nil, err
+	}
+
+	if b := c.cachedBackend(name); b != nil {
+		return b, nil
+	}
+
+	c.mu.Lock()
+	available := make([]string, 0, len(bs))
+	for n := range bs {
+		available = append(available, n)
+	}
+	c.mu.Unlock()
+	sort.Strings(available)
+	return nil, BadBackendErr{backend: name, available: available}
+}
+
+// cachedBackend returns the known backend with the given name, regardless of its case, or nil if it is unknown
+func (c *Client) cachedBackend(name string) *Backend {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b, exists := c.backends[name]; exists {
+		return b
+	}
+	for n, b := range c.backends {
+		if strings.EqualFold(n, name) {
+			return b
+		}
 	}
+	return nil
+}
 
-	var url string
-	if c.opts.hub != "" && c.opts.group != "" && c.opts.project != "" {
-		url = fmt.Sprintf("Network/%s/Groups/%s/Projects/%s/backends", c.opts.hub, c.opts.group, c.opts.project)
-	} else {
-		url = "Backends"
+// backendsUrl returns the backends endpoint, scoped to the IBM Q project when one is configured
+func backendsUrl(opts clientOptions) string {
+	if opts.hub != "" && opts.group != "" && opts.project != "" {
+		return fmt.Sprintf("Network/%s/Groups/%s/Projects/%s/backends", opts.hub, opts.group, opts.project)
 	}
+	return "Backends"
+}
 
-	resp, err := c.conn.get(url, "")
+// fetchBackends retrieves the list of backends from the given backends endpoint
+func (c *Client) fetchBackends(ctx context.Context, url string) ([]*Backend, error) {
+	resp, err := c.conn.getCtx(ctx, url, "")
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var i []*Backend
-	err = c.conn.decode(resp.Body, &i)
+	err = c.conn.decodeChecked(resp.Body, &i)
+	return i, err
+}
+
+// ResolveBackend resolves any known alias, id, or chip name of a backend to the backend itself
+// The known backends are consulted first, followed by the live backend list and OldBackendNames aliases
+func (c *Client) ResolveBackend(ctx context.Context, name string) (*Backend, error) {
+	aliases := backendAliases(name)
+
+	c.mu.Lock()
+	b := matchBackend(c.backends, aliases)
+	c.mu.Unlock()
+	if b != nil {
+		return b, nil
+	}
+
+	live, err := c.fetchBackends(ctx, backendsUrl(c.callOptions()))
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	for _, b := range i {
-		if b.Status == "on" {
-			c.backends[b.Name] = b
+	bs := make(Backends, len(live))
+	for _, b := range live {
+		bs[b.Name] = b
+	}
+
+	if b = matchBackend(bs, aliases); b == nil {
+		return nil, BadBackendErr{backend: name}
+	}
+	return b, nil
+}
+
+// backendAliases returns every lower cased name the given backend name is known by, according to OldBackendNames
+func backendAliases(name string) map[string]bool {
+	name = strings.ToLower(name)
+	aliases := map[string]bool{name: true}
+
+	backendType, exists := OldBackendNames[name]
+	if !exists {
+		return aliases
+	}
+
+	aliases[backendType] = true
+	for alias, t := range OldBackendNames {
+		if t == backendType {
+			aliases[alias] = true
+		}
+	}
+	return aliases
+}
+
+// matchBackend finds the backend whose name, id, chip name, or serial number is one of the given aliases
+func matchBackend(bs Backends, aliases map[string]bool) *Backend {
+	names := make([]string, 0, len(bs))
+	for name := range bs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b := bs[name]
+		for _, id := range []string{b.Name, b.Id, b.ChipName, b.SerialNum} {
+			if id != "" && aliases[strings.ToLower(id)] {
+				return b
+			}
 		}
 	}
+	return nil
+}
+
+// SetBackendCache seeds the clients known backends, so backends can be resolved without calling AvailableBackends
+func (c *Client) SetBackendCache(backends Backends) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return c.backends
+	c.backends = make(map[string]*Backend, len(backends))
+	for name, b := range backends {
+		c.backends[name] = b
+	}
 }
 
 func (c *Client) checkBackend(backendName, endpoint string) string {
@@ -115,6 +349,32 @@ type paramsMeasure struct {
 	Unit string		`json:"unit,omitempty"`
 }
 
+// secondsPerUnit and hertzPerUnit are the scales of the units the API reports times and frequencies in
+var (
+	secondsPerUnit = map[string]float64{"s": 1, "ms": 1e-3, "µs": 1e-6, "μs": 1e-6, "us": 1e-6, "ns": 1e-9}
+	hertzPerUnit = map[string]float64{"hz": 1, "khz": 1e3, "mhz": 1e6, "ghz": 1e9}
+)
+
+// Seconds returns the measurement in seconds, e.g. for T1 and T2 times
+// An error is returned if the unit isn't a unit of time
+func (m paramsMeasure) Seconds() (float64, error) {
+	scale, ok := secondsPerUnit[strings.TrimSpace(m.Unit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit of time: %q", m.Unit)
+	}
+	return m.Value * scale, nil
+}
+
+// Hertz returns the measurement in hertz, e.g. for qubit frequencies
+// An error is returned if the unit isn't a unit of frequency
+func (m paramsMeasure) Hertz() (float64, error) {
+	scale, ok := hertzPerUnit[strings.ToLower(strings.TrimSpace(m.Unit))]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit of frequency: %q", m.Unit)
+	}
+	return m.Value * scale, nil
+}
+
 // Status represents the status of a backend
 type Status struct {
 	Type string			`json:"backend,omitempty"`
@@ -123,36 +383,142 @@ type Status struct {
 	PendingJob int64	`json:"lengthQueue,omitempty"`
 }
 
-// TODO: Possibly wrap up Status, Calibration, and Parameters into one method
+// String returns a summary of the status, e.g. "ibmqx4: available, idle, 3 pending jobs"
+func (s Status) String() string {
+	available, busy := "unavailable", "idle"
+	if s.Available {
+		available = "available"
+	}
+	if s.Busy {
+		busy = "busy"
+	}
+	return s.Type + ": " + available + ", " + busy + ", " + strconv.FormatInt(s.PendingJob, 10) + " pending jobs"
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+// The API returns state and busy either as booleans or as strings, e.g. "on"/"off", so both are accepted
+func (s *Status) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type string					`json:"backend,omitempty"`
+		Available json.RawMessage	`json:"state,omitempty"`
+		Busy json.RawMessage		`json:"busy,omitempty"`
+		PendingJob int64			`json:"lengthQueue,omitempty"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	available, err := decodeFlexBool(raw.Available)
+	if err != nil {
+		return err
+	}
+
+	busy, err := decodeFlexBool(raw.Busy)
+	if err != nil {
+		return err
+	}
+
+	*s = Status{Type: raw.Type, Available: available, Busy: busy, PendingJob: raw.PendingJob}
+	return nil
+}
+
+// decodeFlexBool decodes a boolean which may have been encoded as a string
+func decodeFlexBool(raw json.RawMessage) (bool, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return false, nil
+	}
+
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "on", "true", "yes", "1", "active", "online":
+		return true, nil
+	case "off", "false", "no", "0", "", "inactive", "offline":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean value: %q", str)
+}
+
+// BackendInfo combines the status, calibration and parameters of a chip
+type BackendInfo struct {
+	Status Status				`json:"status"`
+	Calibration Calibration		`json:"calibration"`
+	Params Params				`json:"parameters"`
+}
+
+// BackendInfo retrieves the status, calibration and parameters of a chip concurrently
+// The first error encountered is returned
+func (c *Client) BackendInfo(ctx context.Context, backend string) (BackendInfo, error) {
+	opts := c.callOptions()
+
+	backendType := c.checkBackend(backend, "status")
+	if backendType == "" {
+		return BackendInfo{}, BadBackendErr{backend: backend}
+	}
+
+	var info BackendInfo
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		info.Status, err = c.backendStatus(gctx, opts, backendType)
+		return
+	})
+	g.Go(func() (err error) {
+		info.Calibration, err = c.backendCalibration(gctx, opts, backendType)
+		return
+	})
+	g.Go(func() (err error) {
+		info.Params, err = c.backendParameters(gctx, opts, backendType)
+		return
+	})
+	if err := g.Wait(); err != nil {
+		return BackendInfo{}, err
+	}
+
+	return info, nil
+}
+
 // BackendStatus retrieves the status of a chip
-func (c *Client) BackendStatus(backend string) Status {
+// The hub option is optional, and like calibrations, the status is scoped to the hub when one is configured
+func (c *Client) BackendStatus(backend string, hub ...ClientOption) (Status, error) {
+	opts := c.callOptions(hub...)
+
 	backendType := c.checkBackend(backend, "status")
 	if backendType == "" {
-		log.Fatalf("unknown backend type: %s", backendType)
+		return Status{}, BadBackendErr{backend: backend}
 	}
 
-	resp, err := c.conn.get(fmt.Sprintf("Backends/%s/queue/status", backendType), "withToken=false")
+	return c.backendStatus(context.Background(), opts, backendType)
+}
+
+func (c *Client) backendStatus(ctx context.Context, opts clientOptions, backendType string) (Status, error) {
+	url := getBackendStatsUrl(opts, backendType)
+	resp, err := c.conn.getCtx(ctx, url + "/queue/status", "&withToken=false")
 	if err != nil {
-		log.Fatalln(err)
+		return Status{}, err
 	}
 	defer resp.Body.Close()
 
 	var r Status
-	err = c.conn.decode(resp.Body, &r)
+	err = c.conn.decodeChecked(resp.Body, &r)
 	if err != nil {
-		log.Fatalln(err)
+		return Status{}, err
 	}
 
 	r.Type = backendType
-	return r
+	return r, nil
 }
 
-func (c *Client) getBackendStatsUrl(backendType string) string {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if c.opts.hub != "" {
-		return fmt.Sprintf("Networks/%s/devices/%s", c.opts.hub, backendType)
+func getBackendStatsUrl(opts clientOptions, backendType string) string {
+	if opts.hub != "" {
+		return fmt.Sprintf("Networks/%s/devices/%s", opts.hub, backendType)
 	}
 	return fmt.Sprintf("Backends/%s", backendType)
 }
@@ -175,35 +541,70 @@ type Calibration struct {
 
 // BackendCalibration retrieves the calibration of a chip
 // The hub option is optional
-func (c *Client) BackendCalibration(backend string, hub ClientOption) Calibration {
-	if hub != nil {
-		hub(c.opts)
-	}
+func (c *Client) BackendCalibration(backend string, hub ClientOption) (Calibration, error) {
+	opts := c.callOptions(hub)
 
 	backendType := c.checkBackend(backend, "calibration")
 	if backendType == "" {
-		log.Fatalf("unknown backend type: %s", backendType)
+		return Calibration{}, BadBackendErr{backend: backend}
 	}
 
+	return c.backendCalibration(context.Background(), opts, backendType)
+}
+
+func (c *Client) backendCalibration(ctx context.Context, opts clientOptions, backendType string) (Calibration, error) {
 	if backendType == "sim_trivial_2" {
-		return Calibration{Type: backendType}
+		return Calibration{Type: backendType}, nil
 	}
 
-	url := c.getBackendStatsUrl(backendType)
-	resp, err := c.conn.get(url + "/calibration", "")
+	url := getBackendStatsUrl(opts, backendType)
+	resp, err := c.conn.getCtx(ctx, url + "/calibration", "")
 	if err != nil {
-		log.Fatalln(err)
+		return Calibration{}, err
 	}
 	defer resp.Body.Close()
 
 	var h Calibration
-	err = c.conn.decode(resp.Body, &h)
+	err = c.conn.decodeChecked(resp.Body, &h)
 	if err != nil {
-		log.Fatalln(err)
+		return Calibration{}, err
 	}
 
 	h.Type = backendType
-	return h
+	return h, nil
+}
+
+// BackendCalibrationHistory retrieves up to limit past calibrations of a chip, for analysing their drift
+// Simulators have no calibrations, so none are returned for them
+func (c *Client) BackendCalibrationHistory(backend string, limit int) ([]Calibration, error) {
+	opts := c.callOptions()
+
+	backendType := c.checkBackend(backend, "calibration")
+	if backendType == "" {
+		return nil, BadBackendErr{backend: backend}
+	}
+
+	if backendType == "sim_trivial_2" {
+		return nil, nil
+	}
+
+	url := getBackendStatsUrl(opts, backendType)
+	resp, err := c.conn.get(url + "/calibration/history", fmt.Sprintf("&limit=%d", limit))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var h []Calibration
+	err = c.conn.decodeChecked(resp.Body, &h)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range h {
+		h[i].Type = backendType
+	}
+	return h, nil
 }
 
 // Params represents the calibration parameters for a backend
@@ -227,32 +628,90 @@ type Params struct {
 
 // BackendParameters retrieves the calibration parameters of a real chip
 // The hub option is optional
-func (c *Client) BackendParameters(backend string, hub ClientOption) Params {
-	if hub != nil {
-		hub(c.opts)
-	}
+func (c *Client) BackendParameters(backend string, hub ClientOption) (Params, error) {
+	opts := c.callOptions(hub)
 
 	backendType := c.checkBackend(backend, "calibration")
 	if backendType == "" {
-		log.Fatalf("unknown backend type: %s", backendType)
+		return Params{}, BadBackendErr{backend: backend}
 	}
 
+	return c.backendParameters(context.Background(), opts, backendType)
+}
+
+func (c *Client) backendParameters(ctx context.Context, opts clientOptions, backendType string) (Params, error) {
 	if backendType == "sim_trivial_2" {
-		return Params{Type: backendType}
+		return Params{Type: backendType}, nil
 	}
 
-	url := c.getBackendStatsUrl(backendType)
-	resp, err := c.conn.get(url + "/parameters", "")
+	url := getBackendStatsUrl(opts, backendType)
+	resp, err := c.conn.getCtx(ctx, url + "/parameters", "")
 	if err != nil {
-		log.Fatalln(err)
+		return Params{}, err
 	}
 	defer resp.Body.Close()
 
 	var h Params
-	err = c.conn.decode(resp.Body, &h)
+	err = c.conn.decodeChecked(resp.Body, &h)
+	if err != nil {
+		return Params{}, err
+	}
+
+	return h, nil
+}
+
+// BackendDefaults represents the default gate and pulse parameters of a real chip
+type BackendDefaults struct {
+	Type string						`json:"backend,omitempty"`
+	QubitFreqEst []float64			`json:"qubit_freq_est,omitempty"`	// GHz
+	MeasFreqEst []float64			`json:"meas_freq_est,omitempty"`	// GHz
+	Buffer float64					`json:"buffer,omitempty"`
+	GateDurations []GateDuration	`json:"gate_durations,omitempty"`
+	PulseLibrary []Pulse			`json:"pulse_library,omitempty"`
+}
+
+// GateDuration is the default duration of a gate on the given qubits
+type GateDuration struct {
+	Name string		`json:"name,omitempty"`
+	Qubits []int	`json:"qubits,omitempty"`
+	Duration float64	`json:"duration,omitempty"`	// ns
+}
+
+// Pulse is a named pulse, whose samples are [real, imaginary] pairs
+type Pulse struct {
+	Name string				`json:"name,omitempty"`
+	Samples [][2]float64	`json:"samples,omitempty"`
+}
+
+// BackendDefaults retrieves the default gate and pulse parameters of a real chip
+// Simulators have no defaults, so an error is returned for them
+func (c *Client) BackendDefaults(ctx context.Context, backend string) (BackendDefaults, error) {
+	opts := c.callOptions()
+
+	backendType := c.checkBackend(backend, "defaults")
+	if backendType == "" {
+		return BackendDefaults{}, BadBackendErr{backend: backend}
+	}
+
+	c.mu.Lock()
+	b := c.backends[backend]
+	c.mu.Unlock()
+	if backendType == "sim_trivial_2" || (b != nil && b.Simulator) {
+		return BackendDefaults{}, ApiErr{usrMsg: fmt.Sprintf("backend \"%s\" is a simulator and has no defaults", backend)}
+	}
+
+	resp, err := c.conn.getCtx(ctx, getBackendStatsUrl(opts, backendType) + "/defaults", "")
+	if err != nil {
+		return BackendDefaults{}, err
+	}
+	defer resp.Body.Close()
+
+	var d BackendDefaults
+	err = c.conn.decodeChecked(resp.Body, &d)
 	if err != nil {
-		log.Fatalln(err)
+		return BackendDefaults{}, err
 	}
 
-	return h
-}
\ No newline at end of file
+	d.Type = backendType
+	return d, nil
+}
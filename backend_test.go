@@ -2,10 +2,23 @@ package qiskit_api_go
 
 import (
 	"testing"
+	"encoding/json"
+	"reflect"
+	"net/http"
+	"context"
+	"fmt"
+	"sync"
+	"sort"
+	"math"
+	"errors"
+	"strings"
 )
 
 func TestClient_AvailableBackends(t *testing.T) {
-	backends := testClient.AvailableBackends()
+	backends, err := newReplayClient(t).AvailableBackends()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if len(backends) < 2 {
 		t.Fail()
 	}
@@ -18,22 +31,604 @@ func TestClient_AvailableBackends(t *testing.T) {
 }
 
 func TestClient_BackendStatus(t *testing.T) {
-	status := testClient.BackendStatus("ibmqx4")
+	requireLive(t)
+	status, err := testClient.BackendStatus("ibmqx4")
+	if err != nil {
+		t.Fatal(err)
+	}
 	if status.Type != "ibmqx4" {
 		t.Fail()
 	}
 }
 
 func TestClient_BackendCalibration(t *testing.T) {
-	calibration := testClient.BackendCalibration("ibmqx4", nil)
+	requireLive(t)
+	calibration, err := testClient.BackendCalibration("ibmqx4", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if calibration.MultiQubitGates == nil {
 		t.Fail()
 	}
 }
 
+func TestClient_BackendCalibrationHistory(t *testing.T) {
+	var limit string
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Backends/ibmqx4/calibration/history" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		limit = r.URL.Query().Get("limit")
+		w.Write([]byte(`[
+			{"lastUpdateDate": "2018-03-02T10:00:00.000Z", "qubits": [{"name": "Q0", "readoutError": {"value": 0.05}}]},
+			{"lastUpdateDate": "2018-03-01T10:00:00.000Z", "qubits": [{"name": "Q0", "readoutError": {"value": 0.04}}]},
+			{"lastUpdateDate": "2018-02-28T10:00:00.000Z", "qubits": [{"name": "Q0", "readoutError": {"value": 0.03}}]}
+		]`))
+	}))
+	client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+	history, err := client.BackendCalibrationHistory("ibmqx4", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if limit != "3" {
+		t.Errorf("expected limit 3 but got %s", limit)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 calibrations but got %d", len(history))
+	}
+	if history[0].Type != "ibmqx4" || history[2].LastUpdateDate != "2018-02-28T10:00:00.000Z" || len(history[1].Qubits) != 1 {
+		t.Errorf("unexpected calibration history: %+v", history)
+	}
+
+	t.Run("simulator", func(t2 *testing.T) {
+		client := NewClient(nil)
+		client.SetBackendCache(Backends{"sim_trivial_2": &Backend{Name: "sim_trivial_2", Simulator: true}})
+
+		history, err := client.BackendCalibrationHistory("sim_trivial_2", 3)
+		if err != nil || len(history) != 0 {
+			t2.Errorf("expected no calibrations for the simulator but got %v, %v", history, err)
+		}
+	})
+}
+
+func TestClient_BackendInfo(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/Backends/ibmqx4/queue/status":
+			w.Write([]byte(`{"state": true, "busy": false, "lengthQueue": 3}`))
+		case "/Backends/ibmqx4/calibration":
+			w.Write([]byte(`{"lastUpdateDate": "2018-03-02T10:00:00.000Z"}`))
+		case "/Backends/ibmqx4/parameters":
+			w.Write([]byte(`{"qubits": [{"name": "Q0", "T1": {"value": 50, "unit": "µs"}}]}`))
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+	}))
+	client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+	info, err := client.BackendInfo(context.Background(), "ibmqx4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(paths)
+	expected := []string{"/Backends/ibmqx4/calibration", "/Backends/ibmqx4/parameters", "/Backends/ibmqx4/queue/status"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected requests to %v but got %v", expected, paths)
+	}
+	if info.Status.Type != "ibmqx4" || info.Status.PendingJob != 3 {
+		t.Errorf("unexpected status: %+v", info.Status)
+	}
+	if info.Calibration.LastUpdateDate != "2018-03-02T10:00:00.000Z" {
+		t.Errorf("unexpected calibration: %+v", info.Calibration)
+	}
+	if len(info.Params.Qubits) != 1 || info.Params.Qubits[0].T1.Value != 50 {
+		t.Errorf("unexpected parameters: %+v", info.Params)
+	}
+
+	t.Run("api_error", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"error": {"status": 400, "code": "BACKEND_NOT_AVAILABLE", "message": "backend is offline"}}`))
+		}))
+		client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+		if _, err := client.BackendStatus("ibmqx4"); err == nil {
+			t2.Error("expected the API error object to be returned instead of an empty status")
+		}
+	})
+
+	t.Run("error", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/Backends/ibmqx4/parameters" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{}`))
+		}))
+		client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+		if _, err := client.BackendInfo(context.Background(), "ibmqx4"); err == nil {
+			t2.Error("expected the failed parameters request to be returned")
+		}
+	})
+}
+
 func TestClient_BackendParameters(t *testing.T) {
-	params := testClient.BackendParameters("ibmqx4", nil)
+	requireLive(t)
+	params, err := testClient.BackendParameters("ibmqx4", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if params.Qubits == nil {
 		t.Fail()
 	}
-}
\ No newline at end of file
+}
+
+func TestParamsMeasure_Units(t *testing.T) {
+	testCases := []struct {
+		name string
+		measure paramsMeasure
+		seconds float64
+		hertz float64
+		timeErr bool
+		freqErr bool
+	}{
+		{name: "GHz", measure: paramsMeasure{Value: 5.25, Unit: "GHz"}, hertz: 5.25e9, timeErr: true},
+		{name: "MHz", measure: paramsMeasure{Value: 250, Unit: "MHz"}, hertz: 250e6, timeErr: true},
+		{name: "µs", measure: paramsMeasure{Value: 50, Unit: "µs"}, seconds: 50e-6, freqErr: true},
+		{name: "ns", measure: paramsMeasure{Value: 100, Unit: "ns"}, seconds: 100e-9, freqErr: true},
+		{name: "unknown", measure: paramsMeasure{Value: 0.02, Unit: "K"}, timeErr: true, freqErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			seconds, err := testCase.measure.Seconds()
+			if (err != nil) != testCase.timeErr {
+				t2.Errorf("unexpected error from Seconds: %v", err)
+			}
+			if math.Abs(seconds - testCase.seconds) > 1e-15 {
+				t2.Errorf("expected %g seconds but got %g", testCase.seconds, seconds)
+			}
+
+			hertz, err := testCase.measure.Hertz()
+			if (err != nil) != testCase.freqErr {
+				t2.Errorf("unexpected error from Hertz: %v", err)
+			}
+			if math.Abs(hertz - testCase.hertz) > 1e-3 {
+				t2.Errorf("expected %g hertz but got %g", testCase.hertz, hertz)
+			}
+		})
+	}
+}
+
+func TestClient_UnknownBackend(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to the API: %s", r.URL.Path)
+	}))
+	client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+	testCases := map[string]func() error{
+		"BackendStatus": func() error { _, err := client.BackendStatus("ibmqx42"); return err },
+		"BackendCalibration": func() error { _, err := client.BackendCalibration("ibmqx42", nil); return err },
+		"BackendParameters": func() error { _, err := client.BackendParameters("ibmqx42", nil); return err },
+	}
+
+	for name, call := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			err := call()
+
+			var badBackendErr BadBackendErr
+			if !errors.As(err, &badBackendErr) {
+				t2.Fatalf("expected a BadBackendErr but got: %v", err)
+			}
+			if badBackendErr.backend != "ibmqx42" {
+				t2.Errorf("expected the unknown backend to be reported but got %s", badBackendErr.backend)
+			}
+		})
+	}
+}
+
+func TestBackend_String(t *testing.T) {
+	testCases := []struct {
+		backend Backend
+		expected string
+	}{
+		{backend: Backend{Name: "ibmqx4", Nqubits: 5, Status: "on"}, expected: "ibmqx4 (5 qubits, real, on)"},
+		{backend: Backend{Name: "ibmq_qasm_simulator", Nqubits: 32, Simulator: true, Status: "on"}, expected: "ibmq_qasm_simulator (32 qubits, simulator, on)"},
+	}
+
+	for _, testCase := range testCases {
+		if s := testCase.backend.String(); s != testCase.expected {
+			t.Errorf("expected %q but got %q", testCase.expected, s)
+		}
+	}
+}
+
+func TestStatus_String(t *testing.T) {
+	status := Status{Type: "ibmqx4", Available: true, Busy: true, PendingJob: 3}
+	if s, expected := status.String(), "ibmqx4: available, busy, 3 pending jobs"; s != expected {
+		t.Errorf("expected %q but got %q", expected, s)
+	}
+
+	status = Status{Type: "ibmqx4"}
+	if s, expected := fmt.Sprint(status), "ibmqx4: unavailable, idle, 0 pending jobs"; s != expected {
+		t.Errorf("expected %q but got %q", expected, s)
+	}
+}
+
+func TestCouplingMap_UnmarshalJSON(t *testing.T) {
+	expected := [][2]int{{0, 1}, {0, 2}, {1, 2}}
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{name: "pairs", body: `[[0, 1], [0, 2], [1, 2]]`},
+		{name: "neighbor_lists", body: `[[0, [1, 2]], [1, [2]]]`},
+		{name: "mixed", body: `[[0, [1, 2]], [1, 2]]`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var cm CouplingMap
+			if err := json.Unmarshal([]byte(testCase.body), &cm); err != nil {
+				t2.Fatal(err)
+			}
+
+			if cm.IsAllToAll() || !reflect.DeepEqual(cm.Edges(), expected) {
+				t2.Errorf("expected edges %v but got %v", expected, cm.Edges())
+			}
+		})
+	}
+
+	t.Run("all_to_all", func(t2 *testing.T) {
+		var b Backend
+		if err := json.Unmarshal([]byte(`{"name": "ibmq_qasm_simulator", "couplingMap": "all-to-all"}`), &b); err != nil {
+			t2.Fatal(err)
+		}
+		if !b.CouplingMap.IsAllToAll() || b.CouplingMap.Edges() != nil {
+			t2.Errorf("expected an all-to-all coupling map but got %+v", b.CouplingMap)
+		}
+
+		out, err := json.Marshal(b.CouplingMap)
+		if err != nil || string(out) != `"all-to-all"` {
+			t2.Errorf("expected the coupling map to marshal back to \"all-to-all\" but got %s", out)
+		}
+	})
+
+	t.Run("unknown_string", func(t2 *testing.T) {
+		var cm CouplingMap
+		if err := json.Unmarshal([]byte(`"some-to-some"`), &cm); err == nil {
+			t2.Error("expected an unknown coupling map to be rejected")
+		}
+	})
+}
+
+func TestClient_SetBackendCache(t *testing.T) {
+	client := newMockClient(t, jobsHandler(t, "job-1"))
+
+	client.SetBackendCache(Backends{
+		DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true, Status: "on"},
+	})
+
+	job := NewJob([]string{testExpStr}, 1, 3)
+	if err := client.RunJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.checkBackend("ibmqx4", "job") != "" {
+		t.Error("expected unseeded backend to be unknown")
+	}
+}
+
+func TestStatus_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+		available bool
+		busy bool
+	}{
+		{name: "booleans", body: `{"backend": "ibmqx4", "state": true, "busy": false, "lengthQueue": 3}`, available: true, busy: false},
+		{name: "strings", body: `{"backend": "ibmqx4", "state": "on", "busy": "true", "lengthQueue": 3}`, available: true, busy: true},
+		{name: "off", body: `{"backend": "ibmqx4", "state": "off", "busy": "false", "lengthQueue": 3}`, available: false, busy: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var status Status
+			if err := json.Unmarshal([]byte(testCase.body), &status); err != nil {
+				t2.Fatal(err)
+			}
+
+			if status.Available != testCase.available || status.Busy != testCase.busy {
+				t2.Errorf("expected available=%v busy=%v but got %+v", testCase.available, testCase.busy, status)
+			}
+			if status.Type != "ibmqx4" || status.PendingJob != 3 {
+				t2.Errorf("unexpected status: %+v", status)
+			}
+		})
+	}
+}
+
+func TestClient_BackendCalibration_ConcurrentHubs(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`{"lastUpdateDate": %q}`, r.URL.Path)))
+	}))
+	client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			hub := fmt.Sprintf("hub-%d", i)
+			calibration, err := client.BackendCalibration("ibmqx4", WithIbmQInfo(hub, "group", "project"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			expected := fmt.Sprintf("/Networks/%s/devices/ibmqx4/calibration", hub)
+			if calibration.LastUpdateDate != expected {
+				t.Errorf("expected request to %s but got %s", expected, calibration.LastUpdateDate)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if opts := client.callOptions(); opts.hub != "" {
+		t.Errorf("expected per call hub not to leak into the client but got %s", opts.hub)
+	}
+}
+
+func TestClient_ResolveBackend(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Backends" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`[
+			{"name": "ibmq_5_yorktown", "id": "yorktown-id", "chipName": "ibmqx2", "status": "on"},
+			{"name": "ibmqx4", "id": "tenerife-id", "chipName": "Sparrow", "serialNumber": "Real5Qv2", "status": "on"},
+			{"name": "ibmqx_qasm_simulator", "id": "sim-id", "simulator": true, "status": "on"}
+		]`))
+	}))
+
+	testCases := []struct {
+		alias string
+		expected string
+	}{
+		{alias: "ibmqx4", expected: "ibmqx4"},
+		{alias: "IBMQX4", expected: "ibmqx4"},
+		{alias: "tenerife-id", expected: "ibmqx4"},
+		{alias: "sparrow", expected: "ibmqx4"},
+		{alias: "Real5Qv2", expected: "ibmqx4"},
+		{alias: "ibmq_5_yorktown", expected: "ibmq_5_yorktown"},
+		{alias: "ibmqx2", expected: "ibmq_5_yorktown"},
+		{alias: "simulator", expected: "ibmqx_qasm_simulator"},
+		{alias: "sim_trivial_2", expected: "ibmqx_qasm_simulator"},
+	}
+
+	for _, testCase := range testCases {
+		b, err := client.ResolveBackend(context.Background(), testCase.alias)
+		if err != nil {
+			t.Errorf("resolving %s: %s", testCase.alias, err)
+			continue
+		}
+		if b.Name != testCase.expected {
+			t.Errorf("expected %s to resolve to %s but got %s", testCase.alias, testCase.expected, b.Name)
+		}
+	}
+
+	if _, err := client.ResolveBackend(context.Background(), "not-a-backend"); err == nil {
+		t.Error("expected an unknown backend to fail to resolve")
+	} else if _, ok := err.(BadBackendErr); !ok {
+		t.Errorf("expected a BadBackendErr but got: %v", err)
+	}
+}
+
+func TestClient_BackendStatus_Hub(t *testing.T) {
+	var paths []string
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"state": true, "busy": false, "lengthQueue": 0}`))
+	}), WithIbmQInfo("my-hub", "my-group", "my-project"))
+	client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+	if _, err := client.BackendStatus("ibmqx4"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.BackendStatus("ibmqx4", WithIbmQInfo("other-hub", "my-group", "my-project")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"/Networks/my-hub/devices/ibmqx4/queue/status", "/Networks/other-hub/devices/ibmqx4/queue/status"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected network scoped status urls %v but got %v", expected, paths)
+	}
+}
+
+func TestClient_BackendDefaults(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Backends/ibmqx4/defaults" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"qubit_freq_est": [5.25, 5.30],
+			"meas_freq_est": [6.5, 6.6],
+			"buffer": 10,
+			"gate_durations": [{"name": "cx", "qubits": [1, 0], "duration": 350}, {"name": "u3", "qubits": [0], "duration": 100}],
+			"pulse_library": [{"name": "gaussian", "samples": [[0.1, 0], [0.2, 0.05]]}]
+		}`))
+	}))
+	client.SetBackendCache(Backends{
+		"ibmqx4": &Backend{Name: "ibmqx4"},
+		"ibmq_qasm_simulator": &Backend{Name: "ibmq_qasm_simulator", Simulator: true},
+	})
+
+	defaults, err := client.BackendDefaults(context.Background(), "ibmqx4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if defaults.Type != "ibmqx4" || len(defaults.QubitFreqEst) != 2 || defaults.Buffer != 10 {
+		t.Errorf("unexpected defaults: %+v", defaults)
+	}
+	if len(defaults.GateDurations) != 2 || defaults.GateDurations[0].Name != "cx" || defaults.GateDurations[0].Duration != 350 {
+		t.Errorf("unexpected gate durations: %+v", defaults.GateDurations)
+	}
+	if len(defaults.PulseLibrary) != 1 || defaults.PulseLibrary[0].Samples[1] != [2]float64{0.2, 0.05} {
+		t.Errorf("unexpected pulse library: %+v", defaults.PulseLibrary)
+	}
+
+	if _, err = client.BackendDefaults(context.Background(), "ibmq_qasm_simulator"); err == nil {
+		t.Error("expected an error for a simulator")
+	}
+}
+
+func TestOldBackendNames(t *testing.T) {
+	plausible := map[string]bool{"real": true, "ibmqx3": true, "sim_trivial_2": true}
+	client := NewClient(nil)
+
+	for alias := range OldBackendNames {
+		t.Run(alias, func(t2 *testing.T) {
+			backendType := client.checkBackend(alias, "experiment")
+			if !plausible[backendType] {
+				t2.Errorf("expected alias %s to resolve to a known backend type but got %q", alias, backendType)
+			}
+		})
+	}
+}
+
+func TestBackends_Filters(t *testing.T) {
+	backends := Backends{
+		"ibmqx2": &Backend{Name: "ibmqx2", Nqubits: 5, Status: "on"},
+		"ibmqx5": &Backend{Name: "ibmqx5", Nqubits: 16, Status: "off"},
+		"ibmq_16_melbourne": &Backend{Name: "ibmq_16_melbourne", Nqubits: 14, Status: "on"},
+		"ibmq_qasm_simulator": &Backend{Name: "ibmq_qasm_simulator", Nqubits: 32, Status: "on", Simulator: true},
+	}
+
+	names := func(bs Backends) []string {
+		var names []string
+		for name := range bs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	testCases := []struct {
+		name string
+		filtered Backends
+		expected []string
+	}{
+		{name: "reals", filtered: backends.Reals(), expected: []string{"ibmq_16_melbourne", "ibmqx2", "ibmqx5"}},
+		{name: "min_qubits", filtered: backends.WithMinQubits(14), expected: []string{"ibmq_16_melbourne", "ibmq_qasm_simulator", "ibmqx5"}},
+		{name: "online", filtered: backends.Online(), expected: []string{"ibmq_16_melbourne", "ibmq_qasm_simulator", "ibmqx2"}},
+		{name: "chained", filtered: backends.Reals().Online().WithMinQubits(6), expected: []string{"ibmq_16_melbourne"}},
+		{name: "none", filtered: backends.WithMinQubits(64), expected: nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			if got := names(testCase.filtered); !reflect.DeepEqual(got, testCase.expected) {
+				t2.Errorf("expected backends %v but got %v", testCase.expected, got)
+			}
+		})
+	}
+
+	if len(backends) != 4 {
+		t.Error("expected filtering to leave the original backends untouched")
+	}
+}
+
+func TestClient_Backend(t *testing.T) {
+	var fetches int
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Backends" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		fetches++
+		w.Write([]byte(`[
+			{"name": "ibmqx4", "status": "on"},
+			{"name": "ibmqx_qasm_simulator", "simulator": true, "status": "on"}
+		]`))
+	}))
+	client.SetBackendCache(Backends{"ibmqx2": &Backend{Name: "ibmqx2", Status: "on"}})
+
+	t.Run("hit", func(t2 *testing.T) {
+		b, err := client.Backend("ibmqx2")
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if b.Name != "ibmqx2" || fetches != 0 {
+			t2.Errorf("expected the cached backend without refreshing but got %v after %d refreshes", b, fetches)
+		}
+	})
+
+	t.Run("refresh", func(t2 *testing.T) {
+		b, err := client.Backend("ibmqx4")
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if b.Name != "ibmqx4" || fetches != 1 {
+			t2.Errorf("expected the backend after one refresh but got %v after %d refreshes", b, fetches)
+		}
+
+		if _, err := client.Backend("IBMQX4"); err != nil || fetches != 1 {
+			t2.Errorf("expected the refreshed backend to be cached but got %v after %d refreshes", err, fetches)
+		}
+	})
+
+	t.Run("not_found", func(t2 *testing.T) {
+		_, err := client.Backend("ibmqx9")
+		if _, ok := err.(BadBackendErr); !ok {
+			t2.Fatalf("expected a BadBackendErr but got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "ibmqx4, ibmqx_qasm_simulator") {
+			t2.Errorf("expected the error to list the available backends but got: %v", err)
+		}
+	})
+}
+
+func TestClient_AvailableBackends_Replace(t *testing.T) {
+	responses := []string{
+		`[{"name": "ibmqx2", "status": "on"}, {"name": "ibmqx4", "status": "on"}]`,
+		`[{"name": "ibmqx2", "status": "on"}, {"name": "ibmqx4", "status": "off"}]`,
+	}
+	var calls int
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responses[calls]))
+		calls++
+	}))
+
+	for _, expected := range [][]string{{"ibmqx2", "ibmqx4"}, {"ibmqx2"}} {
+		backends, err := client.AvailableBackends()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for name := range backends {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("expected backends %v but got %v", expected, names)
+		}
+	}
+
+	if _, cached := client.backends["ibmqx4"]; cached {
+		t.Error("expected the offline backend to be removed from the known backends")
+	}
+	if client.checkBackend("ibmqx4", "job") != "" {
+		t.Error("expected the offline backend to no longer be accepted")
+	}
+}
@@ -0,0 +1,97 @@
+package qiskit_api_go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CircuitBuilder assembles simple circuits without having to write QASM by hand
+// The qreg and creg sizes are inferred from the highest qubit and classical bit used
+// A negative index is recorded as an error, which is returned by QASM
+type CircuitBuilder struct {
+	ops []string
+	nQubits int
+	nClbits int
+	err error
+}
+
+// NewCircuitBuilder returns an empty CircuitBuilder
+func NewCircuitBuilder() *CircuitBuilder {
+	return &CircuitBuilder{}
+}
+
+// H applies a Hadamard gate to qubit q
+func (cb *CircuitBuilder) H(q int) *CircuitBuilder {
+	return cb.gate("h", q)
+}
+
+// X applies a Pauli-X gate to qubit q
+func (cb *CircuitBuilder) X(q int) *CircuitBuilder {
+	return cb.gate("x", q)
+}
+
+// CX applies a controlled-X gate with control qubit c and target qubit t
+func (cb *CircuitBuilder) CX(c, t int) *CircuitBuilder {
+	return cb.gate("cx", c, t)
+}
+
+// Measure measures qubit q into classical bit c
+func (cb *CircuitBuilder) Measure(q, c int) *CircuitBuilder {
+	cb.useQubit(q)
+	if c < 0 {
+		cb.fail(fmt.Sprintf("invalid classical bit index: %d", c))
+	} else if c+1 > cb.nClbits {
+		cb.nClbits = c + 1
+	}
+	cb.ops = append(cb.ops, fmt.Sprintf("measure q[%d] -> c[%d];", q, c))
+	return cb
+}
+
+// QASM returns the circuit as OpenQASM 2.0, or the first error recorded while building it
+func (cb *CircuitBuilder) QASM() (string, error) {
+	if cb.err != nil {
+		return "", cb.err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("OPENQASM 2.0;\n")
+	sb.WriteString("include \"qelib1.inc\";\n")
+	if cb.nQubits > 0 {
+		fmt.Fprintf(&sb, "qreg q[%d];\n", cb.nQubits)
+	}
+	if cb.nClbits > 0 {
+		fmt.Fprintf(&sb, "creg c[%d];\n", cb.nClbits)
+	}
+	for _, op := range cb.ops {
+		sb.WriteString(op)
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+func (cb *CircuitBuilder) gate(name string, qubits ...int) *CircuitBuilder {
+	args := make([]string, len(qubits))
+	for i, q := range qubits {
+		cb.useQubit(q)
+		args[i] = fmt.Sprintf("q[%d]", q)
+	}
+	cb.ops = append(cb.ops, fmt.Sprintf("%s %s;", name, strings.Join(args, ",")))
+	return cb
+}
+
+func (cb *CircuitBuilder) useQubit(q int) {
+	if q < 0 {
+		cb.fail(fmt.Sprintf("invalid qubit index: %d", q))
+		return
+	}
+	if q+1 > cb.nQubits {
+		cb.nQubits = q + 1
+	}
+}
+
+// fail records the first error of the circuit, later ones are usually a consequence of it
+func (cb *CircuitBuilder) fail(msg string) {
+	if cb.err == nil {
+		cb.err = ApiErr{usrMsg: msg}
+	}
+}
@@ -0,0 +1,44 @@
+package qiskit_api_go
+
+import (
+	"testing"
+)
+
+func TestCircuitBuilder_QASM(t *testing.T) {
+	qasm, err := NewCircuitBuilder().H(0).CX(0, 1).Measure(0, 0).Measure(1, 1).QASM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `OPENQASM 2.0;
+include "qelib1.inc";
+qreg q[2];
+creg c[2];
+h q[0];
+cx q[0],q[1];
+measure q[0] -> c[0];
+measure q[1] -> c[1];
+`
+	if qasm != expected {
+		t.Errorf("unexpected bell state qasm:\n%s", qasm)
+	}
+}
+
+func TestCircuitBuilder_QASM_NegativeIndex(t *testing.T) {
+	testCases := []struct {
+		name string
+		cb *CircuitBuilder
+	}{
+		{name: "qubit", cb: NewCircuitBuilder().H(0).CX(0, -1)},
+		{name: "classical_bit", cb: NewCircuitBuilder().H(0).Measure(0, -1)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			qasm, err := testCase.cb.QASM()
+			if err == nil {
+				t2.Errorf("expected a negative index to be rejected but got:\n%s", qasm)
+			}
+		})
+	}
+}
@@ -1,32 +1,48 @@
 package qiskit_api_go
 
 import (
-	log "github.com/sirupsen/logrus"
-	"os"
-	"regexp"
 	"fmt"
 	"sync"
 	"time"
+	"encoding/json"
+	"sort"
+	"context"
+	"strconv"
+	"strings"
+	"bytes"
+	"net/url"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"io"
 )
 
-func init() {
-	// Set up logger
-	log.SetOutput(os.Stdout)
-}
-
 type clientOptions struct {
 	// API User specific data
 	clientAppl string
 
 	// Job Execution stuff
 	backend string
+	defaultBackend string
 	shots int
 	name string
+	namePrefix string
 	timeout time.Duration
 	seed uint64
 	maxCredits int
+	hpc bool	// run on the HPC simulator
 	mso bool	// HPC multi_shot_optimization
 	omp int		// HPC omp_num_threads
+	strict bool	// error instead of clamping on soft limits
+	validate bool	// validate qasm locally before submitting
+	resultTransform func(ExpResult) (ExpResult, error)
+	submitHook func(*JobRequest) error
+	pollInterval time.Duration
+	memory bool	// return the outcome of every shot
+	dedupe bool	// only submit unique circuits
+	noiseModel *NoiseModel	// simulator noise
+	qasmVersion string	// OpenQASM version of experiments
+	creditsTTL time.Duration	// how long GetMyCreditsCached returns cached credits
 
 	// IBM Q Info
 	hub string
@@ -41,45 +57,65 @@ const (
 	DefaultMSO = true
 	// DefaultOMP is the default HPC omp number of threads value
 	DefaultOMP = 16
+	// MaxOMP is the maximum HPC omp number of threads value
+	MaxOMP = 16
 )
 
 // MaxSeed is the maximum seed value
 const MaxSeed uint64 = 9999999999
 
+// minRandomSeed is the smallest 10 digit seed, which WithRandomSeed generates seeds from
+const minRandomSeed uint64 = 1000000000
+
 // ClientOption configures how the client is set up
-type ClientOption func(clientOptions)
+type ClientOption func(*clientOptions)
 
 // WithClientApplication specifies which client is using the QX Platform
 func WithClientApplication(appl string) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.clientAppl = DefaultClientAppl + ":" + appl
 	}
 }
 
 // WithBackend
 func WithBackend(backend string) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.backend = backend
 	}
 }
 
+// WithDefaultBackend configures the backend used by the client when no backend is given with WithBackend
+func WithDefaultBackend(backend string) ClientOption {
+	return func(options *clientOptions) {
+		options.defaultBackend = backend
+	}
+}
+
 // WithShots
 func WithShots(shots int) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.shots = shots
 	}
 }
 
 // WithName
 func WithName(name string) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.name = name
 	}
 }
 
+// WithNamePrefix configures the client to name jobs and experiments with the prefix and an incrementing number, e.g. prefix-1
+// The numbers count per client and prefix, and RunJobs numbers its jobs in order. A name given by WithName takes precedence.
+func WithNamePrefix(prefix string) ClientOption {
+	return func(options *clientOptions) {
+		options.namePrefix = prefix
+	}
+}
+
 // JobTimeout
 func JobTimeout(timeout time.Duration) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.timeout = timeout
 	}
 }
@@ -87,39 +123,123 @@ func JobTimeout(timeout time.Duration) ClientOption {
 // WithSeed configures the client to seed simulators before Jobs are ran with the given seed value
 // Note: the seed value must be less than 11 digits long
 func WithSeed(seed uint64) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.seed = seed
 	}
 }
 
+// WithRandomSeed configures the client with a random 10 digit seed, so simulations are reproducible without picking a seed
+// The seed is generated once, so every Job ran by the client uses it. The seed is reported in the results' additional data.
+func WithRandomSeed() ClientOption {
+	seed := minRandomSeed + uint64(rand.Int63n(int64(MaxSeed - minRandomSeed + 1)))
+	return WithSeed(seed)
+}
+
 // WithMaxCredits
 func WithMaxCredits(credits int) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.maxCredits = credits
 	}
 }
 
 // WithHPC configures the client to run jobs on the HPC simulator with the provided configuration values
 // mso = multi_shot_optimization
-// omp = omp_num_threads (must be between 1 and 16)
+// omp = omp_num_threads (must be between 1 and 16, 0 uses DefaultOMP)
+// Out of range values are clamped, or rejected when the job is ran under WithStrictLimits
 func WithHPC(mso bool, omp int) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
+		options.hpc = true
 		options.mso = mso
 		options.omp = omp
 	}
 }
 
+// WithStrictLimits configures the client to return errors when soft limits, such as MaxShots, are exceeded
+// By default values exceeding soft limits are clamped to the limit
+func WithStrictLimits() ClientOption {
+	return func(options *clientOptions) {
+		options.strict = true
+	}
+}
+
+// WithValidation configures whether QASM is validated locally with ValidateQASM before it is submitted
+// Validation is on by default
+func WithValidation(validate bool) ClientOption {
+	return func(options *clientOptions) {
+		options.validate = validate
+	}
+}
+
+// WithResultTransform configures a hook which post-processes every result before it is returned, e.g. for readout-error mitigation
+// Any error returned by the transform is returned to the caller
+func WithResultTransform(transform func(ExpResult) (ExpResult, error)) ClientOption {
+	return func(options *clientOptions) {
+		options.resultTransform = transform
+	}
+}
+
+// WithSubmitHook configures a hook which is called with every job and experiment request just before it is sent
+// The hook may modify the request, or veto it by returning an error, which is then returned to the caller
+func WithSubmitHook(hook func(*JobRequest) error) ClientOption {
+	return func(options *clientOptions) {
+		options.submitHook = hook
+	}
+}
+
+// WithPollInterval configures how often the client polls the API while waiting on results
+func WithPollInterval(interval time.Duration) ClientOption {
+	return func(options *clientOptions) {
+		options.pollInterval = interval
+	}
+}
+
+// WithMemory configures whether jobs return the measured outcome of every shot, in addition to the aggregate counts
+func WithMemory(memory bool) ClientOption {
+	return func(options *clientOptions) {
+		options.memory = memory
+	}
+}
+
+// WithDedupeCircuits configures the client to only submit the unique circuits of a Job,
+// the results of which are then fanned back out to every identical circuit
+func WithDedupeCircuits() ClientOption {
+	return func(options *clientOptions) {
+		options.dedupe = true
+	}
+}
+
+// WithNoiseModel configures the noise model used when running on a simulator
+func WithNoiseModel(model NoiseModel) ClientOption {
+	return func(options *clientOptions) {
+		options.noiseModel = &model
+	}
+}
+
+// WithQASMVersion configures the OpenQASM version experiments are written in, either "2.0" or "3.0"
+// By default experiments are OpenQASM 2.0
+func WithQASMVersion(version string) ClientOption {
+	return func(options *clientOptions) {
+		options.qasmVersion = version
+	}
+}
+
+// WithCreditsCacheTTL configures how long GetMyCreditsCached returns the credits last retrieved,
+// before retrieving them again. By default credits aren't cached
+func WithCreditsCacheTTL(ttl time.Duration) ClientOption {
+	return func(options *clientOptions) {
+		options.creditsTTL = ttl
+	}
+}
+
 // WithIbmQInfo configures the client to use the IBM Q features
 func WithIbmQInfo(hub, group, project string) ClientOption {
-	return func(options clientOptions) {
+	return func(options *clientOptions) {
 		options.hub = hub
 		options.group = group
 		options.project = project
 	}
 }
 
-var maxQubitErrRegex = regexp.MustCompile(`.*register exceed the number of qubits, it can't be greater than (\d+).*`)
-
 // Client represents a concurrent-safe IBM QX API client
 // It implements the same methods as the python client so transferring shouldn't be difficult
 type Client struct {
@@ -129,19 +249,35 @@ type Client struct {
 	conn *Conn
 	backends map[string]*Backend
 	jobs map[string]*Job
+
+	// credits are the credits last retrieved, at creditsFetched
+	credits Credit
+	creditsFetched time.Time
+
+	// nameSeqs are the last numbers used to name jobs by each name prefix
+	nameSeqs map[string]int
+
+	// after waits between polls, it is time.After unless it is faked by tests
+	after func(time.Duration) <-chan time.Time
 }
 
 // NewClient returns a IBMQuantumExperience API Client
 func NewClient(conn *Conn, options ...ClientOption) *Client {
-	var opts clientOptions
+	opts := clientOptions{validate: true}
 	for _, option := range options {
-		option(opts)
+		option(&opts)
 	}
 
 	// Set defaults
 	if opts.clientAppl == "" {
 		opts.clientAppl = DefaultClientAppl
 	}
+	if opts.defaultBackend == "" {
+		opts.defaultBackend = DefaultBackend
+	}
+	if opts.pollInterval <= 0 {
+		opts.pollInterval = DefaultPollInterval
+	}
 
 	// Create client
 	return &Client{
@@ -149,24 +285,138 @@ func NewClient(conn *Conn, options ...ClientOption) *Client {
 		conn: conn,
 		backends: make(map[string]*Backend),
 		jobs: make(map[string]*Job),
+		nameSeqs: make(map[string]int),
+		after: time.After,
+	}
+}
+
+// callOptions returns a copy of the clients options with the given per call options applied
+// This keeps per call options from leaking into the client or racing with other calls
+func (c *Client) callOptions(options ...ClientOption) clientOptions {
+	c.mu.Lock()
+	opts := c.opts
+	c.mu.Unlock()
+
+	for _, option := range options {
+		if option != nil {
+			option(&opts)
+		}
 	}
+	return opts
 }
 
 // Version retrieves the current API version
-func (c *Client) Version() float64 {
-	resp, err := c.conn.get("version", "")
+func (c *Client) Version() (float64, error) {
+	return c.VersionContext(context.Background())
+}
+
+// VersionContext retrieves the current API version, giving up once ctx is done
+func (c *Client) VersionContext(ctx context.Context) (float64, error) {
+	info, err := c.VersionInfo(ctx)
 	if err != nil {
-		log.Error(err)
+		return 0, err
+	}
+
+	return info.Number, nil
+}
+
+// VersionInfo represents the API version along with the features it supports
+type VersionInfo struct {
+	Number float64		`json:"version,omitempty"`
+	String string		`json:"versionString,omitempty"`
+	Features []string	`json:"features,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+// The version is accepted as a bare number, e.g. 5, a version string, e.g. "5.1.0", or an object with both
+func (v *VersionInfo) UnmarshalJSON(b []byte) error {
+	var number float64
+	if err := json.Unmarshal(b, &number); err == nil {
+		*v = VersionInfo{Number: number, String: strconv.FormatFloat(number, 'f', -1, 64)}
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		*v = VersionInfo{String: str, Number: versionNumber(str)}
+		return nil
+	}
+
+	var raw struct {
+		Number float64		`json:"version,omitempty"`
+		String string		`json:"versionString,omitempty"`
+		Features []string	`json:"features,omitempty"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*v = VersionInfo{Number: raw.Number, String: raw.String, Features: raw.Features}
+	if v.Number == 0 {
+		v.Number = versionNumber(v.String)
+	}
+	if v.String == "" && v.Number != 0 {
+		v.String = strconv.FormatFloat(v.Number, 'f', -1, 64)
+	}
+	return nil
+}
+
+// versionNumber parses the major.minor part of a version string, e.g. "5.1.0" is 5.1
+func versionNumber(version string) float64 {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+
+	number, err := strconv.ParseFloat(strings.Join(parts, "."), 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// VersionInfo retrieves the current API version and the features it supports
+func (c *Client) VersionInfo(ctx context.Context) (VersionInfo, error) {
+	resp, err := c.conn.getCtx(ctx, "version", "")
+	if err != nil {
+		return VersionInfo{}, err
 	}
 	defer resp.Body.Close()
 
-	var i float64
-	err = c.conn.decode(resp.Body, &i)
+	var info VersionInfo
+	err = c.conn.decodeChecked(resp.Body, &info)
+	return info, err
+}
+
+// HasFeature reports whether the API supports the given feature
+func (v VersionInfo) HasFeature(feature string) bool {
+	for _, f := range v.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// APIStatus represents the self-reported health of the IBM QX API
+type APIStatus struct {
+	ApiUp bool			`json:"api,omitempty"`
+	BackendsUp bool		`json:"backends,omitempty"`
+	Maintenance bool	`json:"maintenance,omitempty"`
+	Message string		`json:"message,omitempty"`
+}
+
+// APIStatus retrieves the service level health of the API
+func (c *Client) APIStatus(ctx context.Context) (APIStatus, error) {
+	resp, err := c.conn.getCtx(ctx, "status", "")
 	if err != nil {
-		panic(err)
+		return APIStatus{}, err
 	}
+	defer resp.Body.Close()
 
-	return i
+	var status APIStatus
+	err = c.conn.decodeChecked(resp.Body, &status)
+	return status, err
 }
 
 // Credit represents the users credits information
@@ -176,30 +426,109 @@ type Credit struct {
 	Remaining	float64	`json:"remaining,omitempty"`
 }
 
+// String returns a summary of the credits, e.g. "remaining: 10/15, promotional: 0"
+func (c Credit) String() string {
+	return "remaining: " + strconv.FormatFloat(c.Remaining, 'f', -1, 64) + "/" + strconv.FormatFloat(c.MaxUserType, 'f', -1, 64) +
+		", promotional: " + strconv.FormatFloat(c.Promotional, 'f', -1, 64)
+}
+
 type creditsResp struct {
 	Err *httpErr	`json:"error,omitempty"`
 	Cred Credit	`json:"credit,omitempty"`
 }
 
 // GetMyCredits returns the number of remaining credits associated with the given client
-func (c *Client) GetMyCredits() Credit {
-	resp, err := c.conn.get(fmt.Sprintf("users/%s", c.conn.dopts.userId), "")
+func (c *Client) GetMyCredits() (Credit, error) {
+	return c.GetMyCreditsContext(context.Background())
+}
+
+// GetMyCreditsContext returns the number of remaining credits associated with the given client, giving up once ctx is done
+func (c *Client) GetMyCreditsContext(ctx context.Context) (Credit, error) {
+	if err := c.conn.authenticated(); err != nil {
+		return Credit{}, err
+	}
+
+	resp, err := c.conn.getCtx(ctx, fmt.Sprintf("users/%s", c.conn.user()), "")
 	if err != nil {
-		log.Error(err)
+		return Credit{}, err
 	}
 	defer resp.Body.Close()
 
 	var cResp creditsResp
 	err = c.conn.decode(resp.Body, &cResp)
 	if err != nil {
-		log.Fatalln(err)
+		return Credit{}, err
 	}
 
 	if cResp.Err != nil {
-		log.Warn(cResp.Err)
+		return Credit{}, cResp.Err
 	}
 
-	return cResp.Cred
+	c.mu.Lock()
+	c.credits, c.creditsFetched = cResp.Cred, time.Now()
+	c.mu.Unlock()
+
+	return cResp.Cred, nil
+}
+
+// GetMyCreditsCached returns the credits last retrieved if they are fresher than the TTL configured by WithCreditsCacheTTL,
+// otherwise the credits are retrieved again. Running jobs and experiments invalidates the cached credits
+func (c *Client) GetMyCreditsCached() (Credit, error) {
+	ttl := c.callOptions().creditsTTL
+
+	c.mu.Lock()
+	credits, fetched := c.credits, c.creditsFetched
+	c.mu.Unlock()
+	if !fetched.IsZero() && time.Since(fetched) < ttl {
+		return credits, nil
+	}
+
+	return c.GetMyCredits()
+}
+
+// invalidateCredits forces GetMyCreditsCached to retrieve the credits again, e.g. after they were spent
+func (c *Client) invalidateCredits() {
+	c.mu.Lock()
+	c.creditsFetched = time.Time{}
+	c.mu.Unlock()
+}
+
+// Network represents an IBM Q hub the user has access to, along with its groups and projects
+// Its names are what WithIbmQInfo expects
+type Network struct {
+	Name string						`json:"name,omitempty"`
+	Title string					`json:"title,omitempty"`
+	Groups map[string]NetworkGroup	`json:"groups,omitempty"`
+}
+
+// NetworkGroup represents a group within an IBM Q hub
+type NetworkGroup struct {
+	Name string							`json:"name,omitempty"`
+	Title string						`json:"title,omitempty"`
+	Projects map[string]NetworkProject	`json:"projects,omitempty"`
+}
+
+// NetworkProject represents a project within an IBM Q group
+type NetworkProject struct {
+	Name string		`json:"name,omitempty"`
+	Title string	`json:"title,omitempty"`
+}
+
+// Networks retrieves the IBM Q hubs, groups and projects the user has access to
+func (c *Client) Networks() ([]Network, error) {
+	resp, err := c.conn.get("Network", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var networks []Network
+	err = c.conn.decodeChecked(resp.Body, &networks)
+	if err != nil {
+		return nil, err
+	}
+
+	return networks, nil
 }
 
 // Code represents a code
@@ -225,7 +554,48 @@ type Code struct {
 	HasMeasure bool			`json:"hasMeasure,omitempty"`
 	Topology string			`json:"topology,omitempty"`
 	HasBloch bool			`json:"hasBloch,omitempty"`
-	GateDefs interface{}	`json:"gateDefinitions,omitempty"`
+	GateDefs GateDefinitions	`json:"gateDefinitions,omitempty"`
+}
+
+// GateDefinitions returns the custom gates defined by the code
+func (code Code) GateDefinitions() []GateDefinition {
+	return code.GateDefs
+}
+
+// GateDefinition represents a custom gate defined within a code
+type GateDefinition struct {
+	Name string	`json:"name,omitempty"`
+	Qasm string	`json:"qasm,omitempty"`
+}
+
+// GateDefinitions is a list of custom gate definitions
+// The API returns these either as a list of gates or as a map of gate name to qasm, so both are accepted
+type GateDefinitions []GateDefinition
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (gd *GateDefinitions) UnmarshalJSON(b []byte) error {
+	var list []GateDefinition
+	if err := json.Unmarshal(b, &list); err == nil {
+		*gd = list
+		return nil
+	}
+
+	var byName map[string]string
+	if err := json.Unmarshal(b, &byName); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	*gd = make(GateDefinitions, 0, len(names))
+	for _, name := range names {
+		*gd = append(*gd, GateDefinition{Name: name, Qasm: byName[name]})
+	}
+	return nil
 }
 
 // GetCode retrieves a code by its id
@@ -236,10 +606,42 @@ func (c *Client) GetCode(codeId string) (code Code, err error) {
 	}
 	defer resp.Body.Close()
 
-	err = c.conn.decode(resp.Body, &code)
+	err = c.conn.decodeChecked(resp.Body, &code)
 	return
 }
 
+// SaveCode saves the qasm as a new code of the user, with the given name
+func (c *Client) SaveCode(name, qasm string) (Code, error) {
+	// Create request body and send it
+	var b bytes.Buffer
+	err := json.NewEncoder(&b).Encode(&JobRequest{Name: name, Qasm: stripQasmHeaders(qasm, DefaultQASMVersion), CodeType: "QASM2"})
+	if err != nil {
+		return Code{}, err
+	}
+
+	resp, err := c.conn.post("Codes", "", &b)
+	if err != nil {
+		return Code{}, err
+	}
+	defer resp.Body.Close()
+
+	// Handle response body
+	var i struct {
+		Err *httpErr	`json:"error,omitempty"`
+		Code
+	}
+	err = c.conn.decode(resp.Body, &i)
+	if err != nil {
+		return Code{}, err
+	}
+
+	if i.Err != nil {
+		return Code{}, i.Err
+	}
+
+	return i.Code, nil
+}
+
 // LatestCodes represents the latest codes associated with the user
 type LatestCodes struct {
 	Err 	*httpErr `json:"error,omitempty"`
@@ -248,57 +650,347 @@ type LatestCodes struct {
 	Codes 	[]Code `json:"codes,omitempty"`
 }
 
-// GetLastCodes returns the last codes of the user
+// GetLastCodes returns the last codes of the user, along with their executions
 func (c *Client) GetLastCodes() (LatestCodes, error) {
-	resp, err := c.conn.get(fmt.Sprintf("users/%s/codes/latest", c.conn.dopts.userId), "&includeExecutions=true")
+	return c.GetLastCodesWithOptions(0, 0, true)
+}
+
+// GetLastCodesWithOptions returns a page of the last codes of the user
+// A non-positive limit or offset leaves the page size or offset to the API
+func (c *Client) GetLastCodesWithOptions(limit, offset int, includeExecutions bool) (LatestCodes, error) {
+	if err := c.conn.authenticated(); err != nil {
+		return LatestCodes{}, err
+	}
+
+	params := fmt.Sprintf("&includeExecutions=%t", includeExecutions)
+	if limit > 0 {
+		params += fmt.Sprintf("&pageSize=%d", limit)
+	}
+	if offset > 0 {
+		params += fmt.Sprintf("&offset=%d", offset)
+	}
+
+	resp, err := c.conn.get(fmt.Sprintf("users/%s/codes/latest", c.conn.user()), params)
 	if err != nil {
-		log.Error(err)
+		c.conn.dopts.logger.Errorf("failed to get the last codes: %s", err)
 		return LatestCodes{}, err
 	}
 	defer resp.Body.Close()
 
 	var i LatestCodes
-	err = c.conn.decode(resp.Body, &i)
+	err = c.conn.decodeChecked(resp.Body, &i)
 	return i, err
 }
 
-// GetImageCode retrieves the image of a code, by its id
+type imageCodeResp struct {
+	Err *httpErr	`json:"error,omitempty"`
+	Url string		`json:"url,omitempty"`
+}
+
+// GetImageCode retrieves the download URL of the image of a code, by its id
 func (c *Client) GetImageCode(codeId string) (string, error) {
-	resp, err := c.conn.get(fmt.Sprintf("Codes/%s/export/png/url", c.conn.dopts.accessToken), "")
+	return c.GetImageCodeContext(context.Background(), codeId)
+}
+
+// GetImageCodeContext retrieves the download URL of the image of a code, giving up once ctx is done
+func (c *Client) GetImageCodeContext(ctx context.Context, codeId string) (string, error) {
+	resp, err := c.conn.getCtx(ctx, fmt.Sprintf("Codes/%s/export/png/url", codeId), "")
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
-	var i interface{}
+	var i imageCodeResp
 	err = c.conn.decode(resp.Body, &i)
 	if err != nil {
-		log.Fatalln(err)
+		return "", err
+	}
+
+	if i.Err != nil {
+		return "", i.Err
+	}
+
+	return i.Url, nil
+}
+
+// maxImageRedirects is the number of redirects GetCodeImagePNG follows before giving up
+const maxImageRedirects = 10
+
+// GetCodeImagePNG downloads the image of a code, by its id, and returns its PNG bytes
+// The download URL is resolved with GetImageCode, and redirects of the storage it points to are followed.
+func (c *Client) GetCodeImagePNG(ctx context.Context, codeId string) ([]byte, error) {
+	imageUrl, err := c.GetImageCodeContext(ctx, codeId)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageUrl, nil)
+	if err != nil {
+		return nil, CodeImageErr{ApiErr: NewApiErr("", fmt.Sprintf("invalid image url %s", imageUrl), err), CodeId: codeId}
+	}
+	req.Header.Set("Accept", "image/png")
+
+	// The image is served by the storage rather than the API, so it is downloaded without the API retries or access token
+	client := *c.conn.c
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxImageRedirects {
+			return http.ErrUseLastResponse
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, CodeImageErr{ApiErr: ApiErr{devMsg: "the image download failed", url: imageUrl, Cause: err}, CodeId: codeId}
+	}
+	imageErr := CodeImageErr{ApiErr: ApiErr{url: resp.Request.URL.String()}, CodeId: codeId, StatusCode: resp.StatusCode, ContentType: resp.Header.Get("Content-Type")}
+
+	if resp.StatusCode != http.StatusOK {
+		imageErr.devMsg = fmt.Sprintf("the image download returned %s: %s", resp.Status, drainBody(resp))
+		return nil, imageErr
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		imageErr.Cause = err
+		imageErr.devMsg = err.Error()
+		return nil, imageErr
+	}
+
+	// Storages may serve the image as a generic binary, in which case its content decides
+	contentType := imageErr.ContentType
+	if contentType == "" || strings.HasPrefix(contentType, "application/octet-stream") {
+		contentType = http.DetectContentType(b)
+	}
+	if !strings.HasPrefix(contentType, "image/png") {
+		imageErr.devMsg = fmt.Sprintf("expected a PNG image but got content type %s", contentType)
+		return nil, imageErr
+	}
+
+	return b, nil
+}
+
+// Execution represents a single run of a code on a backend
+type Execution struct {
+	Id string				`json:"id,omitempty"`
+	// Status is the status of the execution, e.g. RUNNING, or DONE once it completed
+	Status string			`json:"status,omitempty"`
+	CodeId string			`json:"codeId,omitempty"`
+	Shots int				`json:"shots,omitempty"`
+	Seed float64			`json:"seed,omitempty"`
+	Backend string			`json:"backend,omitempty"`
+	DeviceId string			`json:"deviceId,omitempty"`
+	StartDate string		`json:"startDate,omitempty"`
+	EndDate string			`json:"endDate,omitempty"`
+	ModificationDate float64	`json:"modificationDate,omitempty"`
+	Result ExpResult		`json:"result,omitempty"`
+}
+
+// execution converts the execution response into the execution returned to users
+func (r jobExecResp) execution() Execution {
+	codeId := r.Code.Id
+	if codeId == "" {
+		codeId = r.Code.IdCode
 	}
 
-	fmt.Println(i)
-	return "", nil
+	return Execution{
+		Id: r.Id,
+		Status: r.Status.Id,
+		CodeId: codeId,
+		Shots: int(r.Shots),
+		Seed: r.ParamsCustomize.Seed,
+		Backend: r.DeviceRunType,
+		DeviceId: r.DeviceId,
+		StartDate: r.StartDate,
+		EndDate: r.EndDate,
+		ModificationDate: r.ModDate,
+		Result: r.expResult(),
+	}
 }
 
 // GetExecution retrieves an execution, by its ID
-func (c *Client) GetExecution(executionId string) interface{} {
-	resp, err := c.conn.get(fmt.Sprintf("Executions/%s", executionId), "")
+func (c *Client) GetExecution(executionId string) (Execution, error) {
+	i, err := c.fetchExecution(context.Background(), executionId)
+	if err != nil {
+		return Execution{}, err
+	}
+
+	return i.execution(), nil
+}
+
+// ExecutionQuery filters the executions listed by GetExecutions
+type ExecutionQuery struct {
+	// Backend only lists executions run on the given backend
+	Backend string
+	// Status only lists executions in the given status, e.g. DONE
+	Status string
+	// Since and Until only list executions started within the given dates, when set
+	Since, Until time.Time
+	// Limit is the maximum number of executions listed, DefaultJobsLimit is used if it isn't set
+	Limit int
+	// Offset is the number of executions to skip, e.g. to list the next page of executions
+	Offset int
+}
+
+// filter returns the query as a filter for the Executions endpoint, listing the most recent executions first
+func (q ExecutionQuery) filter() (string, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultJobsLimit
+	}
+
+	where := make(map[string]interface{})
+	if q.Backend != "" {
+		where["deviceRunType"] = q.Backend
+	}
+	if q.Status != "" {
+		where["status.id"] = q.Status
+	}
+	switch {
+	case !q.Since.IsZero() && !q.Until.IsZero():
+		where["startDate"] = map[string]interface{}{"between": []time.Time{q.Since, q.Until}}
+	case !q.Since.IsZero():
+		where["startDate"] = map[string]interface{}{"gte": q.Since}
+	case !q.Until.IsZero():
+		where["startDate"] = map[string]interface{}{"lte": q.Until}
+	}
+
+	b, err := json.Marshal(struct {
+		Order string	`json:"order"`
+		Limit int		`json:"limit"`
+		Skip int		`json:"skip,omitempty"`
+		Where map[string]interface{}	`json:"where,omitempty"`
+	}{Order: "startDate DESC", Limit: limit, Skip: q.Offset, Where: where})
+	return string(b), err
+}
+
+// GetExecutions lists the executions of the user matching the query, most recent first
+func (c *Client) GetExecutions(q ExecutionQuery) ([]Execution, error) {
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.conn.get("Executions", "&filter=" + url.QueryEscape(filter))
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var i interface{}
-	err = c.conn.decode(resp.Body, &i)
+	var rs []jobExecResp
+	err = c.conn.decodeChecked(resp.Body, &rs)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
 	}
 
-	fmt.Println(i)
-	return i
+	executions := make([]Execution, len(rs))
+	for i, r := range rs {
+		executions[i] = r.execution()
+	}
+	return executions, nil
 }
 
 // GetResultFromExecution retrieves the results of an execution, by its ID
-func (c *Client) GetResultFromExecution(executionId string) {
+// The result is empty until the execution is done, which its Status tells, see WaitForExecution to wait for it.
+func (c *Client) GetResultFromExecution(executionId string) (ExpResult, error) {
+	return c.resultFromExecution(context.Background(), executionId)
+}
 
-}
\ No newline at end of file
+func (c *Client) resultFromExecution(ctx context.Context, executionId string) (ExpResult, error) {
+	i, err := c.fetchExecution(ctx, executionId)
+	if err != nil {
+		return ExpResult{}, err
+	}
+
+	return c.transformResult(i.expResult())
+}
+
+// fetchExecution retrieves an execution from the Executions endpoint
+func (c *Client) fetchExecution(ctx context.Context, executionId string) (jobExecResp, error) {
+	resp, err := c.conn.getCtx(ctx, fmt.Sprintf("Executions/%s", executionId), "")
+	if err != nil {
+		return jobExecResp{}, err
+	}
+	defer resp.Body.Close()
+
+	var i jobExecResp
+	err = c.conn.decode(resp.Body, &i)
+	if err != nil {
+		return jobExecResp{}, err
+	}
+
+	if i.Err != nil {
+		return jobExecResp{}, i.Err
+	}
+	return i, nil
+}
+
+// transformResult applies the configured result transform, if any
+func (c *Client) transformResult(result ExpResult) (ExpResult, error) {
+	opts := c.callOptions()
+	if opts.resultTransform == nil {
+		return result, nil
+	}
+	return opts.resultTransform(result)
+}
+
+// codeExecutions retrieves all the executions of a code
+func (c *Client) codeExecutions(ctx context.Context, codeId string) ([]jobExecResp, error) {
+	resp, err := c.conn.getCtx(ctx, fmt.Sprintf("Codes/%s/executions", codeId), "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var i []jobExecResp
+	err = c.conn.decodeChecked(resp.Body, &i)
+	return i, err
+}
+
+// GetCodeResults retrieves the results of every execution of a code, by its ID
+func (c *Client) GetCodeResults(ctx context.Context, codeId string) ([]ExpResult, error) {
+	execs, err := c.codeExecutions(ctx, codeId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The executions are listed in full, so their results don't need to be fetched one by one
+	results := make([]ExpResult, 0, len(execs))
+	for _, exec := range execs {
+		result, err := c.transformResult(exec.expResult())
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// RawGet sends a GET request to the given path of the API, e.g. "Backends/status", and returns the response as is
+// params are appended to the query of the request and should be formatted like "&key=value".
+// The request is authenticated and retried like any other request, and error responses are returned as errors.
+// The caller must close the body of the returned response.
+func (c *Client) RawGet(ctx context.Context, path, params string) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, path, params, nil)
+}
+
+// RawPost sends a POST request with the given JSON body to the given path of the API, and returns the response as is
+// Only bodies which can be rewound, e.g. a *bytes.Buffer, are resent when the request is retried.
+// The caller must close the body of the returned response.
+func (c *Client) RawPost(ctx context.Context, path, params string, body io.Reader) (*http.Response, error) {
+	return c.raw(ctx, http.MethodPost, path, params, body)
+}
+
+func (c *Client) raw(ctx context.Context, method, path, params string, body io.Reader) (*http.Response, error) {
+	path = strings.TrimPrefix(path, "/")
+	if _, err := url.Parse(fmt.Sprintf("%s/%s?%s", c.conn.dopts.url, path, params)); err != nil {
+		return nil, ApiErr{usrMsg: fmt.Sprintf("invalid request path: %s", path), devMsg: err.Error()}
+	}
+	return c.conn.do(c.conn.newRequest(ctx, method, path, params, body))
+}
@@ -4,6 +4,21 @@ import (
 	"testing"
 	"os"
 	"flag"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+	"io/ioutil"
+	"net/url"
+	"errors"
+	"sync"
+	"strings"
+	"bytes"
+	"image"
+	"image/png"
 )
 
 // These tests are to mimic the Python unit tests, as well as, test for concurrency safe-ness
@@ -16,34 +31,83 @@ var (
 
 func TestMain(m *testing.M) {
 	flag.Parse()
-	if *apiToken == "" {
-		flag.Usage()
+	if *apiToken != "" {
+		conn, err := Dial(WithApiToken(*apiToken))
+		if err != nil {
+			panic(err)
+		}
+
+		testClient = NewClient(conn)
 	}
 
-	conn, err := Dial(WithApiToken(*apiToken))
+	os.Exit(m.Run())
+}
+
+// newMockClient returns a client which talks to a mock IBM QX API served by the given handler
+func newMockClient(t *testing.T, handler http.Handler, options ...ClientOption) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1))
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 
-	testClient = NewClient(conn)
-	os.Exit(m.Run())
+	return NewClient(conn, options...)
+}
+
+// replayDir holds the recorded IBM QX API responses replayed by newReplayClient
+const replayDir = "testdata/replay"
+
+// newReplayClient returns a client which replays the API responses recorded in testdata/replay
+// Missing recordings are recorded from the real IBM QX API when an API token is given
+func newReplayClient(t *testing.T, options ...ClientOption) *Client {
+	dopts := []DialOption{WithReplay(replayDir)}
+	if *apiToken != "" {
+		dopts = append(dopts, WithApiToken(*apiToken))
+	} else {
+		dopts = append(dopts, WithAccessInfo("replay-token", "replay-user"))
+	}
+
+	conn, err := Dial(dopts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewClient(conn, options...)
+}
+
+// requireLive skips tests which need to talk to the real IBM QX API when no API token was given
+func requireLive(t *testing.T) {
+	if testClient == nil {
+		t.Skip("no API token provided, skipping live test. run: go test -t YOUR_API_TOKEN")
+	}
 }
 
 func TestClient_Version(t *testing.T) {
-	v := testClient.Version()
+	requireLive(t)
+	v, err := testClient.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if v <= 4 {
 		t.Fail()
 	}
 }
 
 func TestClient_GetMyCredits(t *testing.T) {
-	creds := testClient.GetMyCredits()
+	requireLive(t)
+	creds, err := testClient.GetMyCredits()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if creds.Remaining <= 0 {
 		t.Fail()
 	}
 }
 
 func TestClient_GetLastCodes(t *testing.T) {
+	requireLive(t)
 	codes, err := testClient.GetLastCodes()
 	if err != nil {
 		t.Error(err)
@@ -52,4 +116,801 @@ func TestClient_GetLastCodes(t *testing.T) {
 	if codes.Codes == nil {
 		t.Fail()
 	}
-}
\ No newline at end of file
+}
+
+func TestClient_GetLastCodesWithOptions(t *testing.T) {
+	testCases := []struct {
+		name              string
+		limit             int
+		offset            int
+		includeExecutions bool
+		expected          url.Values
+	}{
+		{name: "defaults", includeExecutions: true, expected: url.Values{"includeExecutions": {"true"}}},
+		{name: "paged", limit: 10, offset: 20, expected: url.Values{"includeExecutions": {"false"}, "pageSize": {"10"}, "offset": {"20"}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var query url.Values
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/users/test-user/codes/latest" {
+					t2.Errorf("unexpected request to the API: %s", r.URL.Path)
+				}
+				query = r.URL.Query()
+				w.Write([]byte(`{"total": 1, "count": 1, "codes": [{"id": "code-1"}]}`))
+			}))
+
+			codes, err := client.GetLastCodesWithOptions(testCase.limit, testCase.offset, testCase.includeExecutions)
+			if err != nil {
+				t2.Fatal(err)
+			}
+			if len(codes.Codes) != 1 || codes.Codes[0].Id != "code-1" {
+				t2.Errorf("unexpected codes: %+v", codes)
+			}
+
+			query.Del("access_token")
+			if !reflect.DeepEqual(query, testCase.expected) {
+				t2.Errorf("expected query %v but got %v", testCase.expected, query)
+			}
+		})
+	}
+}
+
+func TestClient_ContextCancelled(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+
+	testCases := map[string]func(context.Context) error{
+		"VersionContext": func(ctx context.Context) error { _, err := client.VersionContext(ctx); return err },
+		"GetMyCreditsContext": func(ctx context.Context) error { _, err := client.GetMyCreditsContext(ctx); return err },
+	}
+
+	for name, call := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20 * time.Millisecond, cancel)
+
+			if err := call(ctx); !errors.Is(err, context.Canceled) {
+				t2.Errorf("expected the request to be cancelled but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_GetMyCreditsCached(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/test-user":
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			w.Write([]byte(`{"credit": {"remaining": 15, "promotional": 0, "maxUserType": 15}}`))
+		case "/codes/execute":
+			w.Write([]byte(`{"id": "exec-1"}`))
+		case "/Jobs":
+			w.Write([]byte(`{"id": "job-1", "status": {"id": "RUNNING"}}`))
+		default:
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+	}), WithCreditsCacheTTL(time.Minute))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	fetches := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+
+	for i := 0; i < 3; i++ {
+		credit, err := client.GetMyCreditsCached()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if credit.Remaining != 15 {
+			t.Errorf("unexpected credit: %+v", credit)
+		}
+	}
+	if n := fetches(); n != 1 {
+		t.Errorf("expected fresh credits to be cached but they were retrieved %d times", n)
+	}
+
+	if _, err := client.RunExperiment(context.Background(), testExpStr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMyCreditsCached(); err != nil {
+		t.Fatal(err)
+	}
+	if n := fetches(); n != 2 {
+		t.Errorf("expected running an experiment to invalidate the credits but they were retrieved %d times", n)
+	}
+
+	if err := client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMyCreditsCached(); err != nil {
+		t.Fatal(err)
+	}
+	if n := fetches(); n != 3 {
+		t.Errorf("expected running a job to invalidate the credits but they were retrieved %d times", n)
+	}
+
+	t.Run("expired", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			w.Write([]byte(`{"credit": {"remaining": 15}}`))
+		}), WithCreditsCacheTTL(10 * time.Millisecond))
+
+		before := fetches()
+		client.GetMyCreditsCached()
+		time.Sleep(20 * time.Millisecond)
+		client.GetMyCreditsCached()
+		if n := fetches() - before; n != 2 {
+			t2.Errorf("expected expired credits to be retrieved again but they were retrieved %d times", n)
+		}
+	})
+}
+
+func TestCredit_String(t *testing.T) {
+	credit := Credit{Remaining: 10, MaxUserType: 15, Promotional: 2.5}
+	if s, expected := credit.String(), "remaining: 10/15, promotional: 2.5"; s != expected {
+		t.Errorf("expected %q but got %q", expected, s)
+	}
+}
+
+func TestCode_GateDefinitions(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{name: "list", body: `{"id": "abc", "gateDefinitions": [{"name": "bell", "qasm": "h a; cx a,b;"}, {"name": "flip", "qasm": "x a;"}]}`},
+		{name: "map", body: `{"id": "abc", "gateDefinitions": {"flip": "x a;", "bell": "h a; cx a,b;"}}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var code Code
+			if err := json.Unmarshal([]byte(testCase.body), &code); err != nil {
+				t2.Fatal(err)
+			}
+
+			gates := code.GateDefinitions()
+			if len(gates) != 2 {
+				t2.Fatalf("expected 2 gate definitions but got %d", len(gates))
+			}
+			if gates[0].Name != "bell" || gates[0].Qasm != "h a; cx a,b;" {
+				t2.Errorf("unexpected gate definition: %+v", gates[0])
+			}
+			if gates[1].Name != "flip" || gates[1].Qasm != "x a;" {
+				t2.Errorf("unexpected gate definition: %+v", gates[1])
+			}
+		})
+	}
+
+	t.Run("null", func(t2 *testing.T) {
+		var code Code
+		if err := json.Unmarshal([]byte(`{"id": "abc", "gateDefinitions": null}`), &code); err != nil {
+			t2.Fatal(err)
+		}
+		if len(code.GateDefinitions()) != 0 {
+			t2.Fail()
+		}
+	})
+}
+
+func TestClient_APIStatus(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"api": true, "backends": false, "maintenance": true, "message": "scheduled maintenance"}`))
+	}))
+
+	status, err := client.APIStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.ApiUp || status.BackendsUp || !status.Maintenance || status.Message != "scheduled maintenance" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestClient_GetCodeResults(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Codes/code-1/executions":
+			w.Write([]byte(`[
+				{"id": "exec-1", "status": {"id": "DONE"}, "code": {"id": "code-1"}, "result": {"data": {"p": {"qubits": [0], "labels": ["0", "1"], "values": [0.5, 0.5]}}}},
+				{"id": "exec-2", "status": {"id": "DONE"}, "code": {"id": "code-1"}, "result": {"data": {"p": {"qubits": [0], "labels": ["0", "1"], "values": [0.25, 0.75]}}}}
+			]`))
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	results, err := client.GetCodeResults(context.Background(), "code-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results but got %d", len(results))
+	}
+	for i, expected := range []float64{0.5, 0.25} {
+		if results[i].Id != fmt.Sprintf("exec-%d", i+1) || results[i].CodeId != "code-1" || results[i].Status != "DONE" {
+			t.Errorf("unexpected result: %+v", results[i])
+		}
+		if values := results[i].Result.Measure.Values; len(values) != 2 || values[0] != expected {
+			t.Errorf("unexpected measurement values: %v", values)
+		}
+	}
+}
+
+func TestClient_GetResultFromExecution_ResultTransform(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "exec-1", "result": {"data": {"p": {"qubits": [0], "labels": ["0", "1"], "values": [0.5, 0.5]}}}}`))
+	})
+
+	relabel := func(result ExpResult) (ExpResult, error) {
+		for i, label := range result.Result.Measure.Labels {
+			result.Result.Measure.Labels[i] = "|" + label + ">"
+		}
+		return result, nil
+	}
+
+	result, err := newMockClient(t, handler, WithResultTransform(relabel)).GetResultFromExecution("exec-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels := result.Result.Measure.Labels; len(labels) != 2 || labels[0] != "|0>" || labels[1] != "|1>" {
+		t.Errorf("expected relabeled results but got: %v", labels)
+	}
+
+	t.Run("error", func(t2 *testing.T) {
+		failing := func(result ExpResult) (ExpResult, error) {
+			return result, fmt.Errorf("mitigation failed")
+		}
+
+		_, err := newMockClient(t2, handler, WithResultTransform(failing)).GetResultFromExecution("exec-1")
+		if err == nil || err.Error() != "mitigation failed" {
+			t2.Errorf("expected transform error but got: %v", err)
+		}
+	})
+}
+
+func TestWithDefaultBackend(t *testing.T) {
+	var deviceRunType string
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deviceRunType = r.URL.Query().Get("deviceRunType")
+		w.Write([]byte(`{"id": "exec-1"}`))
+	}), WithDefaultBackend("ibmqx2"))
+
+	if _, err := client.RunExperiment(context.Background(), testExpStr); err != nil {
+		t.Fatal(err)
+	}
+	if deviceRunType != "real" {
+		t.Errorf("expected the default backend to be used but got %s", deviceRunType)
+	}
+
+	if _, err := client.RunExperiment(context.Background(), testExpStr, WithBackend("simulator")); err != nil {
+		t.Fatal(err)
+	}
+	if deviceRunType != "sim_trivial_2" {
+		t.Errorf("expected the per call backend to be used but got %s", deviceRunType)
+	}
+}
+
+func TestClient_VersionInfo(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+		expected VersionInfo
+	}{
+		{name: "number", body: `5`, expected: VersionInfo{Number: 5, String: "5"}},
+		{name: "string", body: `"5.1.0"`, expected: VersionInfo{Number: 5.1, String: "5.1.0"}},
+		{name: "object", body: `{"version": 5.1, "versionString": "5.1.0", "features": ["jobs", "hubs"]}`, expected: VersionInfo{Number: 5.1, String: "5.1.0", Features: []string{"jobs", "hubs"}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(testCase.body))
+			}))
+
+			info, err := client.VersionInfo(context.Background())
+			if err != nil {
+				t2.Fatal(err)
+			}
+			if !reflect.DeepEqual(info, testCase.expected) {
+				t2.Errorf("expected %+v but got %+v", testCase.expected, info)
+			}
+
+			if v, err := client.Version(); err != nil || v != testCase.expected.Number {
+				t2.Errorf("expected version %v but got %v", testCase.expected.Number, v)
+			}
+
+			b, err := json.Marshal(info)
+			if err != nil {
+				t2.Fatal(err)
+			}
+			var decoded VersionInfo
+			if err := json.Unmarshal(b, &decoded); err != nil || !reflect.DeepEqual(decoded, info) {
+				t2.Errorf("expected %s to decode back to %+v but got %+v", b, info, decoded)
+			}
+		})
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	client := NewClient(nil,
+		WithBackend("ibmqx4"),
+		WithShots(512),
+		WithSeed(42),
+		WithName("test"),
+		JobTimeout(time.Minute),
+		WithMaxCredits(5),
+		WithHPC(false, 4),
+		WithIbmQInfo("hub", "group", "project"),
+	)
+
+	expected := clientOptions{
+		clientAppl: DefaultClientAppl,
+		backend: "ibmqx4",
+		defaultBackend: DefaultBackend,
+		shots: 512,
+		name: "test",
+		timeout: time.Minute,
+		seed: 42,
+		maxCredits: 5,
+		hpc: true,
+		omp: 4,
+		validate: true,
+		pollInterval: DefaultPollInterval,
+		hub: "hub",
+		group: "group",
+		project: "project",
+	}
+	if !reflect.DeepEqual(client.opts, expected) {
+		t.Errorf("expected client options %+v but got %+v", expected, client.opts)
+	}
+
+	t.Run("per_call", func(t2 *testing.T) {
+		opts := client.callOptions(WithShots(1024), nil)
+		if opts.shots != 1024 {
+			t2.Errorf("expected per call shots to be 1024 but got %d", opts.shots)
+		}
+		if client.opts.shots != 512 {
+			t2.Errorf("expected per call options to leave the client shots at 512 but got %d", client.opts.shots)
+		}
+	})
+}
+
+// failingTransport fails every request, as if the API was unreachable
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestClient_FailingTransport(t *testing.T) {
+	conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl("http://qx.invalid/api"), WithRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.c.Transport = failingTransport{}
+
+	client := NewClient(conn)
+	client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+	testCases := map[string]func() error{
+		"Version": func() error { _, err := client.Version(); return err },
+		"GetMyCredits": func() error { _, err := client.GetMyCredits(); return err },
+		"GetImageCode": func() error { _, err := client.GetImageCode("code-1"); return err },
+		"GetExecution": func() error { _, err := client.GetExecution("exec-1"); return err },
+		"AvailableBackends": func() error { _, err := client.AvailableBackends(); return err },
+		"BackendStatus": func() error { _, err := client.BackendStatus("ibmqx4"); return err },
+		"BackendCalibration": func() error { _, err := client.BackendCalibration("ibmqx4", nil); return err },
+		"BackendParameters": func() error { _, err := client.BackendParameters("ibmqx4", nil); return err },
+	}
+
+	for name, call := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			if err := call(); err == nil {
+				t2.Error("expected the transport error to be returned")
+			}
+		})
+	}
+}
+
+func TestClient_Networks(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Network" {
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+		w.Write([]byte(`[{
+			"name": "ibm-q",
+			"title": "IBM Q",
+			"groups": {
+				"open": {
+					"name": "open",
+					"title": "Open",
+					"projects": {
+						"main": {"name": "main", "title": "Main"}
+					}
+				}
+			}
+		}]`))
+	}))
+
+	networks, err := client.Networks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(networks) != 1 || networks[0].Name != "ibm-q" || networks[0].Title != "IBM Q" {
+		t.Fatalf("unexpected networks: %+v", networks)
+	}
+	group, ok := networks[0].Groups["open"]
+	if !ok || group.Name != "open" {
+		t.Fatalf("expected the open group but got: %+v", networks[0].Groups)
+	}
+	if project := group.Projects["main"]; project.Name != "main" || project.Title != "Main" {
+		t.Errorf("unexpected project: %+v", project)
+	}
+}
+
+func TestClient_SaveCode(t *testing.T) {
+	var submitted map[string]interface{}
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/Codes" {
+			t.Errorf("unexpected request to the API: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"id": "code-1", "name": "bell", "codeType": "QASM2", "qasm": "x q[0];", "userId": "test-user"}`))
+	}))
+
+	code, err := client.SaveCode("bell", "OPENQASM 2.0;x q[0];")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{"name": "bell", "qasm": "x q[0];", "codeType": "QASM2"}
+	if !reflect.DeepEqual(submitted, expected) {
+		t.Errorf("expected request body %v but got %v", expected, submitted)
+	}
+	if code.Id != "code-1" || code.Name != "bell" || code.Qasm != "x q[0];" || code.UserId != "test-user" {
+		t.Errorf("unexpected code: %+v", code)
+	}
+
+	t.Run("api_error", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"error": {"status": 400, "code": "QASM_NOT_VALID", "message": "bad qasm"}}`))
+		}))
+		if _, err := client.SaveCode("bad", "x q[;"); err == nil {
+			t2.Error("expected the API error to be returned")
+		}
+	})
+}
+
+func TestClient_GetImageCode(t *testing.T) {
+	const imageUrl = "https://dal.objectstorage.open.softlayer.com/v1/AUTH_abc/codes/code-1.png"
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Codes/code-1/export/png/url" {
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"url": "%s"}`, imageUrl)
+	}))
+
+	url, err := client.GetImageCode("code-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != imageUrl {
+		t.Errorf("expected image url %s but got %s", imageUrl, url)
+	}
+}
+
+func TestClient_GetCodeImagePNG(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "" {
+			t.Error("expected the access token not to be sent to the storage")
+		}
+		switch r.URL.Path {
+		case "/codes/redirect.png":
+			http.Redirect(w, r, "/codes/image.png", http.StatusFound)
+		case "/codes/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(img.Bytes())
+		case "/codes/binary.png":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(img.Bytes())
+		case "/codes/page.png":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>Sign in</body></html>"))
+		case "/codes/loop.png":
+			http.Redirect(w, r, "/codes/loop.png", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer storage.Close()
+
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		codeId := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/Codes/"), "/export/png/url")
+		fmt.Fprintf(w, `{"url": "%s/codes/%s.png"}`, storage.URL, codeId)
+	}))
+
+	testCases := map[string]struct {
+		codeId string
+		statusCode int
+	}{
+		"redirect": {codeId: "redirect", statusCode: http.StatusOK},
+		"octet_stream": {codeId: "binary", statusCode: http.StatusOK},
+		"html": {codeId: "page", statusCode: http.StatusOK},
+		"redirect_loop": {codeId: "loop", statusCode: http.StatusFound},
+		"not_found": {codeId: "missing", statusCode: http.StatusNotFound},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			b, err := client.GetCodeImagePNG(context.Background(), testCase.codeId)
+			if name == "redirect" || name == "octet_stream" {
+				if err != nil {
+					t2.Fatal(err)
+				}
+				if !bytes.Equal(b, img.Bytes()) {
+					t2.Errorf("expected the %d bytes of the image but got %d bytes", img.Len(), len(b))
+				}
+				return
+			}
+
+			var imageErr CodeImageErr
+			if !errors.As(err, &imageErr) {
+				t2.Fatalf("expected a CodeImageErr but got %v", err)
+			}
+			if imageErr.CodeId != testCase.codeId || imageErr.StatusCode != testCase.statusCode {
+				t2.Errorf("unexpected error: %+v", imageErr)
+			}
+			if !errors.As(err, &ApiErr{}) {
+				t2.Error("expected the image error to unwrap to its ApiErr")
+			}
+		})
+	}
+}
+
+func TestClient_GetExecution(t *testing.T) {
+	body, err := ioutil.ReadFile("testdata/execution.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Executions/5a1d0e1c7b5e2e0039b1c9a4" {
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+		w.Write(body)
+	}))
+
+	execution, err := client.GetExecution("5a1d0e1c7b5e2e0039b1c9a4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := execution.Result
+	execution.Result = ExpResult{}
+	expected := Execution{
+		Id: "5a1d0e1c7b5e2e0039b1c9a4",
+		Status: "DONE",
+		CodeId: "5a1d0e1c7b5e2e0039b1c9a3",
+		Shots: 1024,
+		Backend: "real",
+		DeviceId: "c16c5ddebbf8922a7e2a0f5a89cac478",
+		StartDate: "2017-11-28T08:22:20.524Z",
+		EndDate: "2017-11-28T08:22:56.537Z",
+		ModificationDate: 1511857376543,
+	}
+	if !reflect.DeepEqual(execution, expected) {
+		t.Errorf("expected execution %+v but got %+v", expected, execution)
+	}
+
+	if result.Id != expected.Id || result.Status != "DONE" || !reflect.DeepEqual(result.Result.Measure.Labels, []string{"00000", "00011"}) {
+		t.Errorf("unexpected execution result: %+v", result)
+	}
+}
+
+func TestClient_GetExecutions(t *testing.T) {
+	var filter map[string]interface{}
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Executions" {
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("filter")), &filter); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`[{"id": "exec-2", "status": {"id": "DONE"}, "deviceRunType": "ibmqx4"}, {"id": "exec-1", "status": {"id": "DONE"}, "deviceRunType": "ibmqx4"}]`))
+	}))
+
+	since := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2018, 3, 2, 0, 0, 0, 0, time.UTC)
+	executions, err := client.GetExecutions(ExecutionQuery{Backend: "ibmqx4", Status: "DONE", Since: since, Until: until, Limit: 2, Offset: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"order": "startDate DESC",
+		"limit": float64(2),
+		"skip": float64(4),
+		"where": map[string]interface{}{
+			"deviceRunType": "ibmqx4",
+			"status.id": "DONE",
+			"startDate": map[string]interface{}{"between": []interface{}{"2018-03-01T00:00:00Z", "2018-03-02T00:00:00Z"}},
+		},
+	}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Errorf("expected filter %v but got %v", expected, filter)
+	}
+
+	if len(executions) != 2 || executions[0].Id != "exec-2" || executions[1].Backend != "ibmqx4" || executions[1].Status != "DONE" {
+		t.Errorf("unexpected executions: %+v", executions)
+	}
+}
+
+func TestWithRandomSeed(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		var opts clientOptions
+		WithRandomSeed()(&opts)
+
+		if opts.seed < 1000000000 || opts.seed > MaxSeed {
+			t.Fatalf("expected a 10 digit seed but got %d", opts.seed)
+		}
+		if err := validateSeed(opts.seed); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClient_RawGet(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "test-token" {
+			t.Error("expected the raw request to be authenticated")
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/Backends/status" && r.URL.Query().Get("verbose") == "true":
+			w.Header().Set("X-RateLimit-Remaining", "42")
+			w.Write([]byte(`{"state": true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	resp, err := client.RawGet(context.Background(), "/Backends/status", "&verbose=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "42" {
+		t.Errorf("expected the custom header to be readable from the raw response but got %q", remaining)
+	}
+	if b, _ := ioutil.ReadAll(resp.Body); string(b) != `{"state": true}` {
+		t.Errorf("unexpected raw body: %s", b)
+	}
+
+	t.Run("error", func(t2 *testing.T) {
+		if _, err := client.RawGet(context.Background(), "unknown", ""); err == nil {
+			t2.Error("expected an error response to be returned as an error")
+		}
+	})
+}
+
+func TestClient_RawPost(t *testing.T) {
+	var body, contentType string
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "test-token" {
+			t.Error("expected the raw request to be authenticated")
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/Jobs" && r.URL.Query().Get("dryRun") == "true":
+			b, _ := ioutil.ReadAll(r.Body)
+			body, contentType = string(b), r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "job-1"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"status": 400, "code": "QASM_NOT_VALID", "message": "bad qasm"}}`))
+		}
+	}))
+
+	resp, err := client.RawPost(context.Background(), "Jobs", "&dryRun=true", bytes.NewBufferString(`{"name": "raw"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated || body != `{"name": "raw"}` || contentType != "application/json" {
+		t.Errorf("unexpected raw post: %d %s %s", resp.StatusCode, contentType, body)
+	}
+	if b, _ := ioutil.ReadAll(resp.Body); string(b) != `{"id": "job-1"}` {
+		t.Errorf("unexpected raw body: %s", b)
+	}
+
+	t.Run("error", func(t2 *testing.T) {
+		_, err := client.RawPost(context.Background(), "Jobs", "", strings.NewReader(`{}`))
+		if httpErr, ok := err.(*httpErr); !ok || httpErr.Code != "QASM_NOT_VALID" {
+			t2.Errorf("expected the API error to be returned but got: %v", err)
+		}
+	})
+}
+
+func TestClient_WaitForExecution(t *testing.T) {
+	var polls int
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Executions/exec-1":
+			polls++
+			if polls < 3 {
+				w.Write([]byte(`{"id": "exec-1", "status": {"id": "RUNNING"}}`))
+				return
+			}
+			w.Write([]byte(`{"id": "exec-1", "status": {"id": "DONE"}, "result": {"data": {"p": {"labels": ["1"], "values": [1]}}}}`))
+		case "/Executions/exec-2":
+			w.Write([]byte(`{"id": "exec-2", "status": {"id": "ERROR"}}`))
+		default:
+			w.Write([]byte(`{"id": "exec-3", "status": {"id": "RUNNING"}}`))
+		}
+	}))
+
+	t.Run("done", func(t2 *testing.T) {
+		res, err := client.WaitForExecution(context.Background(), "exec-1", time.Millisecond)
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if polls != 3 || res.Status != "DONE" || !reflect.DeepEqual(res.Result.Measure.Labels, []string{"1"}) {
+			t2.Errorf("unexpected result after %d polls: %+v", polls, res)
+		}
+	})
+
+	t.Run("error", func(t2 *testing.T) {
+		_, err := client.WaitForExecution(context.Background(), "exec-2", time.Millisecond)
+		execErr, ok := err.(ExecutionErr)
+		if !ok {
+			t2.Fatalf("expected an ExecutionErr but got: %v", err)
+		}
+		if execErr.ExecutionId != "exec-2" || execErr.Status != "ERROR" {
+			t2.Errorf("unexpected execution error: %v", execErr)
+		}
+		if !errors.As(err, &ApiErr{}) {
+			t2.Error("expected the execution error to unwrap to its ApiErr")
+		}
+	})
+
+	t.Run("context", func(t2 *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+		defer cancel()
+
+		if _, err := client.WaitForExecution(ctx, "exec-3", time.Hour); err != context.DeadlineExceeded {
+			t2.Errorf("expected the context error but got: %v", err)
+		}
+	})
+}
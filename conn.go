@@ -1,12 +1,22 @@
 package qiskit_api_go
 
 import (
+	"context"
+	"math/rand"
+	"strconv"
 	"time"
 	"net/http"
 	"bytes"
 	"encoding/json"
 	"io"
 	"fmt"
+	"strings"
+	"io/ioutil"
+	"net/url"
+	"crypto/tls"
+	"compress/gzip"
+	"sync"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -16,6 +26,15 @@ const (
 	DefaultRetries = 5
 	// DefaultTimeout is the default timeout for each request
 	DefaultTimeout = 30 * time.Second
+	// DefaultBackoff is the default delay before the first retry of a request
+	DefaultBackoff = 250 * time.Millisecond
+	// DefaultMaxBackoff is the default maximum delay between retries of a request
+	DefaultMaxBackoff = 10 * time.Second
+	// DefaultMaxRetryAfter is the default maximum delay before a retry the API can ask for with Retry-After
+	DefaultMaxRetryAfter = time.Minute
+
+	// maxErrBodySnippet is the maximum length of the response body included in errors
+	maxErrBodySnippet = 256
 )
 
 type dialOptions struct {
@@ -25,16 +44,34 @@ type dialOptions struct {
 	password string
 	accessToken string
 	userId string
+	anonymous bool
 
 	// API Endpoint Info
 	url string
 	proxyUrls map[string]string
 	ntmlUsername string
 	ntmlPassword string
+	tlsConfig *tls.Config
+	insecureSkipVerify bool
 
 	// API Request Info
 	retries int
+	retryErrorCodes []string
 	timeout time.Duration
+	backoff time.Duration
+	maxBackoff time.Duration
+	maxRetryAfter time.Duration
+	rateLimit float64
+	rateBurst int
+
+	// HTTP Client Info
+	httpClient *http.Client
+
+	// Logging Info
+	logger Logger
+
+	// Testing Info
+	replayDir string
 }
 
 // DialOption configures how to connection works
@@ -63,6 +100,14 @@ func WithLoginInfo(email, password string) DialOption {
 	}
 }
 
+// WithAnonymous configures the connection to dial without credentials, for the public operations of the API, e.g. listing backends
+// Calls which require credentials fail with a CredentialsErr. Credentials given by other options are still used.
+func WithAnonymous() DialOption {
+	return func(options *dialOptions) {
+		options.anonymous = true
+	}
+}
+
 // WithApiUrl configures the connection to use the provided url for the API endpoints
 func WithApiUrl(url string) DialOption {
 	return func(options *dialOptions) {
@@ -74,7 +119,7 @@ func WithApiUrl(url string) DialOption {
 // urls should be a map of:
 //		http: URL
 //		https: URL
-// ntmlInfo should be length 2 where first value is username and second value is the password for NTML Auth
+// ntmlInfo should be length 2 where first value is username (optionally DOMAIN\user) and second value is the password for NTLM Auth
 func WithProxies(urls map[string]string, ntmlInfo ...string) DialOption {
 	return func(options *dialOptions) {
 		options.proxyUrls = urls
@@ -86,6 +131,30 @@ func WithProxies(urls map[string]string, ntmlInfo ...string) DialOption {
 	}
 }
 
+// WithTLSConfig configures the TLS settings used to connect to the API, e.g. to trust the certificate of an on-prem endpoint
+// The config applies to proxied connections as well
+func WithTLSConfig(config *tls.Config) DialOption {
+	return func(options *dialOptions) {
+		options.tlsConfig = config
+	}
+}
+
+// WithInsecureSkipVerify configures the connection to skip verifying the API certificate, e.g. for self-signed staging endpoints
+// This should never be used against production endpoints
+func WithInsecureSkipVerify(skip bool) DialOption {
+	return func(options *dialOptions) {
+		options.insecureSkipVerify = skip
+	}
+}
+
+// WithRetryOnErrorCode configures the connection to retry responses whose API error code is one of the given codes,
+// e.g. BACKEND_TEMPORARILY_UNAVAILABLE, even when the status code alone would not be retried
+func WithRetryOnErrorCode(codes ...string) DialOption {
+	return func(options *dialOptions) {
+		options.retryErrorCodes = append(options.retryErrorCodes, codes...)
+	}
+}
+
 // WithRetries configures the number of retries performed for any request
 func WithRetries(retries int) DialOption {
 	return func(options *dialOptions) {
@@ -93,6 +162,31 @@ func WithRetries(retries int) DialOption {
 	}
 }
 
+// WithRateLimit configures the connection to send at most rps requests per second, allowing bursts of up to burst requests
+// Requests, including retries, wait for their turn until their context is done. By default, requests aren't limited.
+func WithRateLimit(rps float64, burst int) DialOption {
+	return func(options *dialOptions) {
+		options.rateLimit = rps
+		options.rateBurst = burst
+	}
+}
+
+// WithBackoff configures the delay between retries of a request
+// The delay starts at base and doubles with every retry, up to max, and is randomly jittered so clients don't retry in lockstep
+func WithBackoff(base, max time.Duration) DialOption {
+	return func(options *dialOptions) {
+		options.backoff = base
+		options.maxBackoff = max
+	}
+}
+
+// WithMaxRetryAfter configures the maximum delay before a retry, when the API asks to wait with the Retry-After header
+func WithMaxRetryAfter(max time.Duration) DialOption {
+	return func(options *dialOptions) {
+		options.maxRetryAfter = max
+	}
+}
+
 // WithTimeout configures the timeout for each request
 func WithTimeout(timeout time.Duration) DialOption {
 	return func(options *dialOptions) {
@@ -100,10 +194,41 @@ func WithTimeout(timeout time.Duration) DialOption {
 	}
 }
 
+// WithHTTPClient configures the connection to send its requests with the given client
+// The client is used as is, so WithTimeout, WithProxies and the TLS options don't apply to it and should be configured on the client instead
+func WithHTTPClient(client *http.Client) DialOption {
+	return func(options *dialOptions) {
+		options.httpClient = client
+	}
+}
+
+// WithLogger configures the logger used by the connection and its clients
+// By default, nothing is logged
+func WithLogger(logger Logger) DialOption {
+	return func(options *dialOptions) {
+		options.logger = logger
+	}
+}
+
+// WithReplay configures the connection to record every API response as JSON files in dir,
+// and to replay them from there instead of calling the API once they are recorded
+// This is mostly useful for deterministic tests which can run without an API token
+func WithReplay(dir string) DialOption {
+	return func(options *dialOptions) {
+		options.replayDir = dir
+	}
+}
+
 // Conn is a representation of a connection to the IBM QX API
 type Conn struct {
 	dopts dialOptions
 	c *http.Client
+	limiter *rate.Limiter
+
+	// tokenMu guards the access token and user id, which are replaced when the API rejects the token
+	tokenMu sync.RWMutex
+	// refreshMu serializes token refreshes, so concurrent requests rejected with the same token log in once
+	refreshMu sync.Mutex
 }
 
 // Dial takes a list of DialOptions and returns a connection to the IBM QX API
@@ -117,7 +242,7 @@ func Dial(options ...DialOption) (*Conn, error) {
 	}
 
 	// Check API Login info; otherwise, error
-	if c.dopts.apiToken == "" && c.dopts.email == "" && c.dopts.accessToken == "" {
+	if !c.dopts.anonymous && c.dopts.apiToken == "" && c.dopts.email == "" && c.dopts.accessToken == "" {
 		return nil, CredentialsErr{ApiErr{usrMsg: "missing credentials to obtain access token. please provide either, api token or email/password"}}
 	}
 
@@ -133,16 +258,148 @@ func Dial(options ...DialOption) (*Conn, error) {
 	if c.dopts.timeout == 0 {
 		c.dopts.timeout = DefaultTimeout
 	}
-	c.c.Timeout = c.dopts.timeout
+
+	if c.dopts.backoff <= 0 {
+		c.dopts.backoff = DefaultBackoff
+	}
+
+	if c.dopts.logger == nil {
+		c.dopts.logger = nopLogger{}
+	}
+
+	if c.dopts.maxRetryAfter <= 0 {
+		c.dopts.maxRetryAfter = DefaultMaxRetryAfter
+	}
+
+	if c.dopts.maxBackoff < c.dopts.backoff {
+		c.dopts.maxBackoff = DefaultMaxBackoff
+		if c.dopts.maxBackoff < c.dopts.backoff {
+			c.dopts.maxBackoff = c.dopts.backoff
+		}
+	}
+
+	if c.dopts.rateLimit > 0 {
+		if c.dopts.rateBurst < 1 {
+			c.dopts.rateBurst = 1
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(c.dopts.rateLimit), c.dopts.rateBurst)
+	}
+
+	// A custom client is used as is, besides the replay transport, so it is copied to leave it untouched
+	if c.dopts.httpClient != nil {
+		client := *c.dopts.httpClient
+		c.c = &client
+	} else {
+		c.c.Timeout = c.dopts.timeout
+
+		var transport *http.Transport
+		if len(c.dopts.proxyUrls) > 0 {
+			var err error
+			transport, err = newProxyTransport(c.dopts.proxyUrls, c.dopts.ntmlUsername, c.dopts.ntmlPassword)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if c.dopts.tlsConfig != nil || c.dopts.insecureSkipVerify {
+			if transport == nil {
+				transport = http.DefaultTransport.(*http.Transport).Clone()
+			}
+			transport.TLSClientConfig = c.dopts.tlsClientConfig()
+		}
+
+		if transport != nil {
+			c.c.Transport = transport
+		}
+	}
+
+	if c.dopts.replayDir != "" {
+		c.c.Transport = newReplayTransport(c.dopts.replayDir, c.dopts.url, c.c.Transport)
+	}
 
 	// Lastly, obtain access token
 	var err error
-	if c.dopts.accessToken == "" {
+	if c.dopts.accessToken == "" && !c.isAnonymous() {
 		err = c.obtainToken()
 	}
 	return c, err
 }
 
+// isAnonymous reports whether the connection was dialed without any credentials
+func (c *Conn) isAnonymous() bool {
+	return c.dopts.anonymous && c.dopts.apiToken == "" && c.dopts.email == "" && c.token() == ""
+}
+
+// token returns the current access token
+func (c *Conn) token() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.dopts.accessToken
+}
+
+// user returns the id of the user the access token belongs to
+func (c *Conn) user() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.dopts.userId
+}
+
+// refreshToken obtains a new access token, unless another request already replaced the stale one
+func (c *Conn) refreshToken(stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if c.token() != stale {
+		return nil
+	}
+	return c.obtainToken()
+}
+
+// authenticated returns a CredentialsErr if the connection was dialed without any credentials
+func (c *Conn) authenticated() error {
+	if c.isAnonymous() {
+		return CredentialsErr{ApiErr{usrMsg: "this call requires credentials, but the connection was dialed anonymously", devMsg: "dial with WithApiToken, WithLoginInfo or WithAccessInfo to make this call"}}
+	}
+	return nil
+}
+
+// tlsClientConfig returns the TLS config for the transport, leaving the configured one untouched
+func (opts dialOptions) tlsClientConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if opts.tlsConfig != nil {
+		cfg = opts.tlsConfig.Clone()
+	}
+	if opts.insecureSkipVerify {
+		cfg.InsecureSkipVerify = true
+	}
+	return cfg
+}
+
+// newProxyTransport returns a transport which sends requests through the proxy configured for their scheme
+// Requests with a scheme which has no proxy configured are sent directly.
+// When NTLM credentials are given, requests are tunneled through the proxies with CONNECT, authenticating with NTLM
+func newProxyTransport(urls map[string]string, ntlmUsername, ntlmPassword string) (*http.Transport, error) {
+	proxies := make(map[string]*url.URL, len(urls))
+	for scheme, rawUrl := range urls {
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			return nil, ApiErr{usrMsg: fmt.Sprintf("invalid %s proxy url: %s", scheme, rawUrl), devMsg: err.Error()}
+		}
+		proxies[strings.ToLower(scheme)] = u
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if ntlmUsername != "" {
+		setNtlmDialers(transport, proxies, ntlmUsername, ntlmPassword)
+		return transport, nil
+	}
+
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxies[req.URL.Scheme], nil
+	}
+	return transport, nil
+}
+
 // loginReq is an internal type for making obtainToken requests
 type loginReq struct {
 	Token 		string	`json:"apiToken,omitempty"`
@@ -151,7 +408,7 @@ type loginReq struct {
 }
 
 type loginResp struct {
-	httpErr
+	Err *httpErr	`json:"error,omitempty"`
 	Created string `json:"created"`
 	UserId string `json:"userId"`
 	Id string	`json:"id"`
@@ -178,17 +435,14 @@ func (c *Conn) obtainToken() error {
 		return err
 	}
 
-	// Construct request URL
-	url := c.dopts.url + "/users/login"
-	if loginReq.Token != "" {
-		url += "WithToken"
-	}
-
 	// Create request and execute it
-	req, _ := http.NewRequest(http.MethodPost, url, &b)
+	req, _ := http.NewRequest(http.MethodPost, loginUrl(c.dopts.url, loginReq.Token != ""), &b)
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.do(req)
 	if err != nil {
+		if httpErr, ok := err.(*httpErr); ok {
+			return CredentialsErr{NewApiErr("the API rejected the credentials", "login failed", httpErr)}
+		}
 		return err
 	}
 	defer resp.Body.Close()
@@ -200,22 +454,43 @@ func (c *Conn) obtainToken() error {
 		return err
 	}
 
+	if r.Err != nil {
+		return CredentialsErr{NewApiErr("the API rejected the credentials", "login failed", r.Err)}
+	}
+
 	// Set fields
+	c.tokenMu.Lock()
 	c.dopts.userId = r.UserId
 	c.dopts.accessToken = r.Id
+	c.tokenMu.Unlock()
 
 	return nil
 }
 
+// loginUrl builds the login endpoint URL for the given API base URL
+// Token logins use users/loginWithToken, while email/password logins use users/login
+func loginUrl(baseUrl string, withToken bool) string {
+	path := "users/login"
+	if withToken {
+		path = "users/loginWithToken"
+	}
+	return strings.TrimRight(baseUrl, "/") + "/" + path
+}
+
 // newRequest is simply just a helper for generating requests
-func (c *Conn) newRequest(method, path, params string, body io.Reader) *http.Request {
-	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s?access_token=%s%s", c.dopts.url, path, c.dopts.accessToken, params), body)
+func (c *Conn) newRequest(ctx context.Context, method, path, params string, body io.Reader) *http.Request {
+	query := strings.TrimPrefix(params, "&")
+	if !c.isAnonymous() {
+		query = "access_token=" + c.token() + params
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s?%s", c.dopts.url, path, query), body)
 	if err != nil {
 		panic(err) // TODO: Implement better logging
 	}
 	if method == http.MethodPost || method == http.MethodPut {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	req.Header.Set("Accept-Encoding", "gzip")
 	return req
 }
 
@@ -225,57 +500,398 @@ func (c *Conn) decode(r io.Reader, i interface{}) (err error) {
 	return
 }
 
+// decodeChecked decodes json like decode, unless the API returned an error object instead of the expected value,
+// in which case the error is returned
+func (c *Conn) decodeChecked(r io.Reader, i interface{}) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var peek struct {
+		Err json.RawMessage	`json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(b, &peek); err == nil && len(peek.Err) > 0 && string(peek.Err) != "null" {
+		var httpErr httpErr
+		if err := json.Unmarshal(peek.Err, &httpErr); err != nil {
+			return err
+		}
+		return &httpErr
+	}
+
+	return json.Unmarshal(b, i)
+}
+
 // TODO: Implement better error handling shit
 // Do runs a http request
 // This takes care of setting headers on requests also
 // Note: This shouldn't be used by client but it is here to expose a little lower API if they want to
 func (c *Conn) do(req *http.Request) (resp *http.Response, err error) {
-	retrys := c.dopts.retries
-	for retrys > 0 {
-		// Execute the request
-		resp, err = c.c.Do(req)
-		if err != nil {
-			return // TODO: Investigate this error
+	var retryAfter time.Duration
+	for attempt := 0; attempt < c.dopts.retries; attempt++ {
+		// Back off before retrying, for as long as the API asked if it did
+		if attempt > 0 {
+			delay := c.backoff(attempt)
+			if retryAfter > 0 {
+				delay = retryAfter
+			}
+			c.dopts.logger.Debugf("retrying %s in %s, %d retries remaining", redactUrl(req.URL), delay, c.dopts.retries-attempt)
+			if wErr := wait(req.Context(), delay); wErr != nil {
+				return nil, wErr
+			}
 		}
+		retryAfter = 0
 
-		// Check for 401 and get new token
-		if resp.StatusCode == http.StatusUnauthorized {
-			if err = c.obtainToken(); err != nil {
-				return
+		if c.limiter != nil {
+			if wErr := c.limiter.Wait(req.Context()); wErr != nil {
+				return nil, wErr
 			}
+		}
+
+		resp, err = c.send(req)
+		if err != nil {
+			err = withUrl(err, req)
 
-			resp, err = c.c.Do(req)
+			// Network errors are retried, unless the request was cancelled
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			continue
 		}
 
 		// Check status code
-		if resp.StatusCode != http.StatusOK {
-//			log.Warnf("Got a %d code response to %v", resp.StatusCode, resp.Request.URL)
-			// TODO: Add something better than regex here
-		} else {
-			return
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		if !c.shouldRetry(resp) {
+			return nil, responseErr(req, resp)
+		}
+
+		retryAfter = c.retryAfter(resp, time.Now())
+
+		// Free the connection for the retry, keeping the start of the body for the error
+		status, body := resp.StatusCode, drainBody(resp)
+		err = ApiErr{
+			usrMsg: "Failed to get proper response from backend",
+			devMsg: fmt.Sprintf("last response status: %d body: %s", status, body),
+			url: redactUrl(req.URL),
+		}
+	}
+	return nil, err
+}
+
+// send sends the request once, obtaining a new access token and resending it if the token was rejected
+func (c *Conn) send(req *http.Request) (*http.Response, error) {
+	// Rewind the body so retries resend the full request
+	if err := rewindBody(req); err != nil {
+		return nil, err
+	}
+
+	// Execute the request
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return nil, redactErr(err)
+	}
+	if err = decompress(resp); err != nil {
+		return nil, err
+	}
+
+	// Check for 401 and get new token, unless the login itself was rejected
+	if resp.StatusCode == http.StatusUnauthorized && !strings.Contains(req.URL.Path, "users/login") {
+		drainBody(resp)
+		if err = c.authenticated(); err != nil {
+			return nil, err
+		}
+
+		stale := req.URL.Query().Get("access_token")
+		if err = c.refreshToken(stale); err != nil {
+			return nil, err
+		}
+
+		// Resend the request with the new token in place of the rejected one
+		req.URL.RawQuery = strings.Replace(req.URL.RawQuery, "access_token="+stale, "access_token="+c.token(), 1)
+		if err = rewindBody(req); err != nil {
+			return nil, err
+		}
+		resp, err = c.c.Do(req)
+		if err != nil {
+			return nil, redactErr(err)
+		}
+		if err = decompress(resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// decompress transparently decompresses the body of a gzip encoded response
+// The transport only does so itself when it asked for gzip, which it doesn't once Accept-Encoding is set explicitly.
+func decompress(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(resp.Body)
+	switch {
+	case err == io.EOF:
+		// An empty body has nothing to decompress
+		resp.Body.Close()
+		resp.Body = http.NoBody
+	case err != nil:
+		resp.Body.Close()
+		return ApiErr{usrMsg: "Failed to decompress response from backend", devMsg: err.Error()}
+	default:
+		resp.Body = gzipBody{Reader: zr, body: resp.Body}
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// gzipBody is a decompressed response body, which closes the underlying body as well
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// responseErr returns the error for a failed response which won't be retried, and closes its body
+// Client errors are returned as the API error in their body, or an API error made up from the response otherwise.
+func responseErr(req *http.Request, resp *http.Response) error {
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+
+	snippet := string(b)
+	if len(snippet) > maxErrBodySnippet {
+		snippet = snippet[:maxErrBodySnippet]
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		if httpErr := decodeHttpErr(b); httpErr != nil {
+			httpErr.url = redactUrl(req.URL)
+			return httpErr
+		}
+		return &httpErr{Name: http.StatusText(resp.StatusCode), StatusCode: int64(resp.StatusCode), Message: snippet, url: redactUrl(req.URL)}
+	}
+
+	return ApiErr{
+		usrMsg: "Failed to get proper response from backend",
+		devMsg: fmt.Sprintf("response status: %d body: %s", resp.StatusCode, snippet),
+		url: redactUrl(req.URL),
+	}
+}
+
+// withUrl attaches the redacted URL of the request to the errors of sending it which don't carry it yet
+// Transport errors already name the URL, redacted by send.
+func withUrl(err error, req *http.Request) error {
+	switch e := err.(type) {
+	case ApiErr:
+		if e.url == "" {
+			e.url = redactUrl(req.URL)
+		}
+		return e
+	case *httpErr:
+		if e.url == "" {
+			e.url = redactUrl(req.URL)
+		}
+		return e
+	}
+	return err
+}
+
+// backoff returns the delay before the given retry of a request
+// The delay doubles with every retry up to the maximum backoff, and is jittered between half and all of it
+func (c *Conn) backoff(retry int) time.Duration {
+	delay := c.dopts.backoff
+	for i := 1; i < retry && delay < c.dopts.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.dopts.maxBackoff {
+		delay = c.dopts.maxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2) + 1))
+}
+
+// retryAfter returns how long the API asked to wait before retrying the response, capped by the maximum Retry-After
+// The Retry-After header is either a number of seconds or an HTTP date. 0 is returned if the API didn't ask.
+func (c *Conn) retryAfter(resp *http.Response, now time.Time) time.Duration {
+	header := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if header == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(secs) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = date.Sub(now)
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	if delay > c.dopts.maxRetryAfter {
+		c.dopts.logger.Debugf("API asked to wait %s before retrying, waiting the maximum %s instead", delay, c.dopts.maxRetryAfter)
+		return c.dopts.maxRetryAfter
+	}
+	return delay
+}
+
+// wait waits for the delay, unless ctx is done first
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drainBody reads the rest of the response body and closes it, so its connection can be reused
+// The start of the body is returned, for including it in errors
+func drainBody(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrBodySnippet))
+	io.Copy(ioutil.Discard, resp.Body)
+	return string(snippet)
+}
+
+// redactUrl returns the URL as a string with the access token masked, so it is safe to log
+func redactUrl(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	redacted := *u
+	q := redacted.Query()
+	if q.Get("access_token") != "" {
+		q.Del("access_token")
+		redacted.RawQuery = "access_token=***"
+		if rest := q.Encode(); rest != "" {
+			redacted.RawQuery += "&" + rest
+		}
+	}
+	return redacted.String()
+}
+
+// redactErr masks the access token of the URL in errors returned by the http.Client
+func redactErr(err error) error {
+	if uErr, ok := err.(*url.Error); ok {
+		if u, pErr := url.Parse(uErr.URL); pErr == nil {
+			redacted := *uErr
+			redacted.URL = redactUrl(u)
+			return &redacted
 		}
+	}
+	return err
+}
+
+// shouldRetry reports whether a failed response should be retried
+// Only rate limiting and temporary server errors are retried, as client errors will never succeed
+func (c *Conn) shouldRetry(resp *http.Response) bool {
+	// Configured error codes are always retried, regardless of status code
+	if c.hasRetryableErrorCode(resp) {
+		return true
+	}
 
-		retrys--
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// hasRetryableErrorCode peeks at the response body for an API error code configured by WithRetryOnErrorCode
+// The body is restored afterwards so the response can still be read by the caller
+func (c *Conn) hasRetryableErrorCode(resp *http.Response) bool {
+	if len(c.dopts.retryErrorCodes) == 0 || resp.Body == nil {
+		return false
 	}
 
-	err = ApiErr{usrMsg: "Failed to get proper response from backend"}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return false
+	}
+
+	httpErr := decodeHttpErr(b)
+	if httpErr == nil {
+		return false
+	}
+
+	for _, code := range c.dopts.retryErrorCodes {
+		if httpErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// rewindBody resets the request body, if any, so the request can be safely sent again
+func rewindBody(req *http.Request) (err error) {
+	if req.GetBody == nil {
+		return
+	}
+	req.Body, err = req.GetBody()
 	return
 }
 
 // Post is a convenience wrapper around a POST request
 func (c *Conn) post(path, params string, body io.Reader) (*http.Response, error) {
-	req := c.newRequest(http.MethodPost, path, params, body)
+	return c.postCtx(context.Background(), path, params, body)
+}
+
+// postCtx is post, but the request is cancelled once ctx is done
+func (c *Conn) postCtx(ctx context.Context, path, params string, body io.Reader) (*http.Response, error) {
+	req := c.newRequest(ctx, http.MethodPost, path, params, body)
 	return c.do(req)
 }
 
 // Put is a convenience wrapper around a PUT request
 func (c *Conn) put(path, params string, body io.Reader) (*http.Response, error) {
-	req := c.newRequest(http.MethodPut, path, params, body)
+	return c.putCtx(context.Background(), path, params, body)
+}
+
+// putCtx is put, but the request is cancelled once ctx is done
+func (c *Conn) putCtx(ctx context.Context, path, params string, body io.Reader) (*http.Response, error) {
+	req := c.newRequest(ctx, http.MethodPut, path, params, body)
+	return c.do(req)
+}
+
+// del is a convenience wrapper around a DELETE request
+func (c *Conn) del(path, params string) (*http.Response, error) {
+	return c.delCtx(context.Background(), path, params)
+}
+
+// delCtx is del, but the request is cancelled once ctx is done
+func (c *Conn) delCtx(ctx context.Context, path, params string) (*http.Response, error) {
+	req := c.newRequest(ctx, http.MethodDelete, path, params, nil)
 	return c.do(req)
 }
 
 // Get is a convenience wrapper around a GET request
 func (c *Conn) get(path, params string) (*http.Response, error) {
-	req := c.newRequest(http.MethodGet, path, params, nil)
+	return c.getCtx(context.Background(), path, params)
+}
+
+// getCtx is get, but the request is cancelled once ctx is done
+func (c *Conn) getCtx(ctx context.Context, path, params string) (*http.Response, error) {
+	req := c.newRequest(ctx, http.MethodGet, path, params, nil)
 	return c.do(req)
-}
\ No newline at end of file
+}
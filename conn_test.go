@@ -0,0 +1,760 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"encoding/json"
+	"strings"
+	"context"
+	"errors"
+	"time"
+	"fmt"
+	"reflect"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"sync"
+)
+
+func TestLoginUrl(t *testing.T) {
+	testCases := []struct {
+		baseUrl string
+		withToken bool
+		expected string
+	}{
+		{baseUrl: DefaultUrl, withToken: true, expected: "https://quantumexperience.ng.bluemix.net/api/users/loginWithToken"},
+		{baseUrl: DefaultUrl, withToken: false, expected: "https://quantumexperience.ng.bluemix.net/api/users/login"},
+		{baseUrl: DefaultUrl + "/", withToken: true, expected: "https://quantumexperience.ng.bluemix.net/api/users/loginWithToken"},
+		{baseUrl: DefaultUrl + "/", withToken: false, expected: "https://quantumexperience.ng.bluemix.net/api/users/login"},
+		{baseUrl: "http://localhost:8080//", withToken: true, expected: "http://localhost:8080/users/loginWithToken"},
+		{baseUrl: "http://localhost:8080", withToken: false, expected: "http://localhost:8080/users/login"},
+	}
+
+	for _, testCase := range testCases {
+		if url := loginUrl(testCase.baseUrl, testCase.withToken); url != testCase.expected {
+			t.Errorf("loginUrl(%q, %v): expected %s but got %s", testCase.baseUrl, testCase.withToken, testCase.expected, url)
+		}
+	}
+}
+
+func TestConn_obtainToken_Retry(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.URL.Path != "/users/loginWithToken" {
+			t.Errorf("unexpected login path: %s", r.URL.Path)
+		}
+
+		var req loginReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token != "api-token" {
+			t.Errorf("attempt %d did not resend the login body", attempts)
+		}
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"id": "access-token", "userId": "user-id"}`))
+	}))
+	defer srv.Close()
+
+	conn, err := Dial(WithApiToken("api-token"), WithApiUrl(srv.URL + "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn.dopts.accessToken != "access-token" || conn.dopts.userId != "user-id" {
+		t.Errorf("unexpected login info: %s %s", conn.dopts.accessToken, conn.dopts.userId)
+	}
+}
+
+func TestConn_RefreshToken(t *testing.T) {
+	var mu sync.Mutex
+	var logins, submitted int
+	var staleQueries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/users/loginWithToken":
+			logins++
+			fmt.Fprintf(w, `{"id": "token-%d", "userId": "user-id"}`, logins)
+		case "/Jobs":
+			if token := r.URL.Query().Get("access_token"); token != "token-2" {
+				staleQueries = append(staleQueries, r.URL.RawQuery)
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": {"status": 401, "code": "INVALID_TOKEN", "message": "Invalid Access Token"}}`))
+				return
+			}
+			submitted++
+			fmt.Fprintf(w, `{"id": "job-%d", "status": {"id": "RUNNING"}}`, submitted)
+		default:
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	conn, err := Dial(WithApiToken("api-token"), WithApiUrl(srv.URL), WithRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(conn)
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	// Every submission is rejected with the token of the dial, so they all refresh it concurrently
+	jobs := make([]*Job, 6)
+	for i := range jobs {
+		jobs[i] = NewJob([]string{testExpStr}, 1, 3)
+	}
+	if err := client.RunJobs(context.Background(), jobs, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if logins != 2 {
+		t.Errorf("expected the rejected token to be refreshed once but logged in %d times", logins)
+	}
+	if submitted != len(jobs) {
+		t.Errorf("expected %d submissions with the new token but got %d", len(jobs), submitted)
+	}
+	for _, query := range staleQueries {
+		if !strings.Contains(query, "access_token=token-1") {
+			t.Errorf("expected only the stale token to be rejected but got query: %s", query)
+		}
+	}
+	if token, user := conn.token(), conn.user(); token != "token-2" || user != "user-id" {
+		t.Errorf("unexpected login info: %s %s", token, user)
+	}
+}
+
+func TestConn_RetryOnErrorCode(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"status": 400, "code": "BACKEND_TEMPORARILY_UNAVAILABLE", "message": "try again later"}}`))
+			return
+		}
+		w.Write([]byte(`4.5`))
+	}))
+	defer srv.Close()
+
+	conn, err := Dial(WithAccessInfo("token", "user"), WithApiUrl(srv.URL), WithRetries(2), WithRetryOnErrorCode("BACKEND_TEMPORARILY_UNAVAILABLE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := conn.get("version", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var v float64
+	if err = conn.decode(resp.Body, &v); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 || v != 4.5 {
+		t.Errorf("expected a retry then success but got %d attempts and version %v", attempts, v)
+	}
+}
+
+func TestConn_do_RedactedUrl(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Jobs/job-1":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"statusCode": 404, "name": "Error", "message": "Unknown \"Job\" id", "code": "MODEL_NOT_FOUND"}}`))
+		case "/Jobs":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("forbidden"))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	conn, err := Dial(WithAccessInfo("secret-token", "user"), WithApiUrl(srv.URL), WithRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkUrl := func(t2 *testing.T, err error, expected string) {
+		urlErr, ok := err.(interface{ URL() string })
+		if !ok {
+			t2.Fatalf("expected an error with the request url but got: %v", err)
+		}
+		if urlErr.URL() != expected {
+			t2.Errorf("expected redacted url %s but got %s", expected, urlErr.URL())
+		}
+		if strings.Contains(err.Error(), "secret-token") || !strings.Contains(err.Error(), expected) {
+			t2.Errorf("expected error to include only the redacted url: %s", err)
+		}
+	}
+
+	t.Run("server_error", func(t2 *testing.T) {
+		_, err := conn.get("version", "&foo=bar")
+		if _, ok := err.(ApiErr); !ok {
+			t2.Fatalf("expected an ApiErr but got: %v", err)
+		}
+		checkUrl(t2, err, srv.URL + "/version?access_token=***&foo=bar")
+	})
+
+	t.Run("api_error", func(t2 *testing.T) {
+		_, err := conn.get("Jobs/job-1", "")
+		if httpErr, ok := err.(*httpErr); !ok || !httpErr.notFound() {
+			t2.Fatalf("expected the API error but got: %v", err)
+		}
+		checkUrl(t2, err, srv.URL + "/Jobs/job-1?access_token=***")
+	})
+
+	t.Run("client_error", func(t2 *testing.T) {
+		_, err := conn.get("Jobs", "")
+		var httpErr *httpErr
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusForbidden {
+			t2.Fatalf("expected a forbidden error but got: %v", err)
+		}
+		checkUrl(t2, err, srv.URL + "/Jobs?access_token=***")
+	})
+
+	t.Run("transport_error", func(t2 *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		conn, err := Dial(WithAccessInfo("secret-token", "user"), WithApiUrl(closed.URL), WithRetries(1))
+		if err != nil {
+			t2.Fatal(err)
+		}
+		_, err = conn.get("version", "")
+		if err == nil {
+			t2.Fatal("expected the request to fail")
+		}
+		if expected := closed.URL + "/version?access_token=***"; strings.Contains(err.Error(), "secret-token") || !strings.Contains(err.Error(), expected) {
+			t2.Errorf("expected error to include only the redacted url %s: %s", expected, err)
+		}
+	})
+}
+
+func TestWithProxies(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host + r.URL.Path)
+		w.Write([]byte(`{"version": 5}`))
+	}))
+	defer proxy.Close()
+
+	conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl("http://qx.invalid/api"), WithRetries(1), WithProxies(map[string]string{"http": proxy.URL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewClient(conn).Version(); err != nil {
+		t.Fatal(err)
+	}
+	if len(proxied) != 1 || proxied[0] != "qx.invalid/api/version" {
+		t.Errorf("expected the request to be routed through the proxy but the proxy got: %v", proxied)
+	}
+
+	t.Run("invalid_url", func(t2 *testing.T) {
+		_, err := Dial(WithAccessInfo("test-token", "test-user"), WithProxies(map[string]string{"https": "://proxy"}))
+		if err == nil {
+			t2.Error("expected an invalid proxy url to be rejected")
+		}
+	})
+}
+
+func TestConn_do_ContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20 * time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.RunExperiment(ctx, testExpStr)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the request to be cancelled but got: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected the request to abort as soon as the context was cancelled")
+	}
+}
+
+func TestConn_do_Backoff(t *testing.T) {
+	var attempts []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts = append(attempts, time.Now())
+		if len(attempts) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": {"status": 500, "message": "internal error"}}`))
+			return
+		}
+		w.Write([]byte(`4.5`))
+	}))
+	defer srv.Close()
+
+	conn, err := Dial(WithAccessInfo("token", "user"), WithApiUrl(srv.URL), WithRetries(3), WithBackoff(20 * time.Millisecond, 40 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := conn.get("version", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(attempts) != 3 {
+		t.Fatalf("expected 3 attempts but got %d", len(attempts))
+	}
+	// Jitter keeps at least half of the 20ms then 40ms delays
+	if d := attempts[1].Sub(attempts[0]); d < 10 * time.Millisecond {
+		t.Errorf("expected the first retry to back off but it came after %s", d)
+	}
+	if d := attempts[2].Sub(attempts[1]); d < 20 * time.Millisecond {
+		t.Errorf("expected the second retry to back off longer but it came after %s", d)
+	}
+
+	t.Run("exhausted", func(t2 *testing.T) {
+		attempts = nil
+		conn, err := Dial(WithAccessInfo("token", "user"), WithApiUrl(srv.URL), WithRetries(2), WithBackoff(time.Millisecond, time.Millisecond))
+		if err != nil {
+			t2.Fatal(err)
+		}
+
+		_, err = conn.get("version", "")
+		if err == nil || !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "internal error") {
+			t2.Errorf("expected the last status and body in the error but got: %v", err)
+		}
+	})
+}
+
+func TestConn_do_RetryableStatus(t *testing.T) {
+	testCases := []struct {
+		name string
+		status int
+		attempts int
+	}{
+		{name: "unprocessable", status: http.StatusUnprocessableEntity, attempts: 1},
+		{name: "bad_request", status: http.StatusBadRequest, attempts: 1},
+		{name: "unavailable", status: http.StatusServiceUnavailable, attempts: 3},
+		{name: "rate_limited", status: http.StatusTooManyRequests, attempts: 3},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			attempts := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(testCase.status)
+				fmt.Fprintf(w, `{"error": {"statusCode": %d, "code": "TEST_ERROR", "message": "failed"}}`, testCase.status)
+			}))
+			defer srv.Close()
+
+			conn, err := Dial(WithAccessInfo("token", "user"), WithApiUrl(srv.URL), WithRetries(3), WithBackoff(time.Millisecond, time.Millisecond))
+			if err != nil {
+				t2.Fatal(err)
+			}
+
+			_, err = conn.get("version", "")
+			if err == nil {
+				t2.Fatal("expected an error")
+			}
+			if attempts != testCase.attempts {
+				t2.Errorf("expected %d attempts but got %d", testCase.attempts, attempts)
+			}
+
+			// Client errors are returned as the API error
+			httpErr, ok := err.(*httpErr)
+			if isClientErr := testCase.status < 500 && testCase.status != http.StatusTooManyRequests; isClientErr != ok {
+				t2.Errorf("expected the API error only for client errors but got: %v", err)
+			}
+			if ok && (httpErr.Code != "TEST_ERROR" || httpErr.StatusCode != int64(testCase.status)) {
+				t2.Errorf("unexpected API error: %v", httpErr)
+			}
+		})
+	}
+}
+
+func TestConn_do_RetryAfter(t *testing.T) {
+	newServer := func(retryAfter string) (*httptest.Server, *int) {
+		attempts := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.Header().Set("Retry-After", retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`4.5`))
+		}))
+		return srv, &attempts
+	}
+
+	testCases := []struct {
+		name string
+		retryAfter string
+		maxRetryAfter time.Duration
+		min, max time.Duration
+	}{
+		{name: "seconds", retryAfter: "2", min: 2 * time.Second, max: 3 * time.Second},
+		{name: "capped_date", retryAfter: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), maxRetryAfter: 50 * time.Millisecond, min: 50 * time.Millisecond, max: time.Second},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			srv, attempts := newServer(testCase.retryAfter)
+			defer srv.Close()
+
+			conn, err := Dial(WithAccessInfo("token", "user"), WithApiUrl(srv.URL), WithRetries(2), WithBackoff(time.Millisecond, time.Millisecond), WithMaxRetryAfter(testCase.maxRetryAfter))
+			if err != nil {
+				t2.Fatal(err)
+			}
+
+			start := time.Now()
+			resp, err := conn.get("version", "")
+			if err != nil {
+				t2.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if *attempts != 2 {
+				t2.Errorf("expected a retry then success but got %d attempts", *attempts)
+			}
+			if elapsed := time.Since(start); elapsed < testCase.min || elapsed > testCase.max {
+				t2.Errorf("expected to wait between %s and %s before retrying but waited %s", testCase.min, testCase.max, elapsed)
+			}
+		})
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	var paths []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/users/loginWithToken":
+			w.Write([]byte(`{"id": "access-token", "userId": "user-id"}`))
+		default:
+			w.Write([]byte(`{"version": 5}`))
+		}
+	}))
+	defer srv.Close()
+
+	// Only the test server client trusts the test server certificate, so requests only succeed if it is used
+	httpClient := srv.Client()
+	httpClient.Timeout = time.Minute
+	conn, err := Dial(WithApiToken("api-token"), WithApiUrl(srv.URL), WithRetries(1), WithTimeout(time.Second), WithHTTPClient(httpClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewClient(conn).Version(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"/users/loginWithToken", "/version"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected requests %v through the custom client but got %v", expected, paths)
+	}
+	if conn.c.Timeout != time.Minute {
+		t.Errorf("expected the custom client timeout to be kept but got %s", conn.c.Timeout)
+	}
+}
+
+func TestConn_decodeChecked(t *testing.T) {
+	var c Conn
+
+	t.Run("error", func(t2 *testing.T) {
+		var credit Credit
+		err := c.decodeChecked(strings.NewReader(`{"error": {"status": 401, "code": "AUTHORIZATION_REQUIRED", "message": "Authorization Required"}}`), &credit)
+
+		httpErr, ok := err.(*httpErr)
+		if !ok {
+			t2.Fatalf("expected the API error to be returned but got: %v", err)
+		}
+		if httpErr.Status != 401 || httpErr.Code != "AUTHORIZATION_REQUIRED" {
+			t2.Errorf("unexpected API error: %+v", httpErr)
+		}
+	})
+
+	t.Run("value", func(t2 *testing.T) {
+		var credit Credit
+		if err := c.decodeChecked(strings.NewReader(`{"remaining": 15, "promotional": 0, "maxUserType": 15}`), &credit); err != nil {
+			t2.Fatal(err)
+		}
+		if credit.Remaining != 15 || credit.MaxUserType != 15 {
+			t2.Errorf("unexpected credit: %+v", credit)
+		}
+	})
+
+	t.Run("list", func(t2 *testing.T) {
+		var backends []*Backend
+		if err := c.decodeChecked(strings.NewReader(`[{"name": "ibmqx4"}]`), &backends); err != nil {
+			t2.Fatal(err)
+		}
+		if len(backends) != 1 || backends[0].Name != "ibmqx4" {
+			t2.Errorf("unexpected backends: %+v", backends)
+		}
+	})
+
+	t.Run("null_error", func(t2 *testing.T) {
+		var status APIStatus
+		if err := c.decodeChecked(strings.NewReader(`{"error": null, "api": true}`), &status); err != nil {
+			t2.Fatal(err)
+		}
+		if !status.ApiUp {
+			t2.Errorf("unexpected status: %+v", status)
+		}
+	})
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": 5}`))
+	}))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	t.Run("root_pool", func(t2 *testing.T) {
+		conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1), WithTLSConfig(&tls.Config{RootCAs: pool}))
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if _, err := NewClient(conn).Version(); err != nil {
+			t2.Error(err)
+		}
+	})
+
+	t.Run("untrusted", func(t2 *testing.T) {
+		conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1))
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if _, err := NewClient(conn).Version(); err == nil {
+			t2.Error("expected the self-signed certificate to be rejected")
+		}
+	})
+
+	t.Run("insecure_skip_verify", func(t2 *testing.T) {
+		conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1), WithInsecureSkipVerify(true))
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if _, err := NewClient(conn).Version(); err != nil {
+			t2.Error(err)
+		}
+	})
+
+	t.Run("with_proxies", func(t2 *testing.T) {
+		var tunneled []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodConnect {
+				http.Error(w, "expected CONNECT", http.StatusMethodNotAllowed)
+				return
+			}
+			tunneled = append(tunneled, r.Host)
+
+			upstream, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			defer upstream.Close()
+
+			w.WriteHeader(http.StatusOK)
+			client, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer client.Close()
+			go io.Copy(upstream, buf)
+			io.Copy(client, upstream)
+		}))
+		defer proxy.Close()
+
+		conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1),
+			WithProxies(map[string]string{"https": proxy.URL}), WithTLSConfig(&tls.Config{RootCAs: pool}))
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if _, err := NewClient(conn).Version(); err != nil {
+			t2.Fatal(err)
+		}
+		if host := strings.TrimPrefix(srv.URL, "https://"); len(tunneled) != 1 || tunneled[0] != host {
+			t2.Errorf("expected the request to be tunneled to %s through the proxy but got: %v", host, tunneled)
+		}
+	})
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var arrivals []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		w.Write([]byte(`{"version": 5}`))
+	}))
+	defer srv.Close()
+
+	conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1), WithRateLimit(20, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(conn)
+
+	const n = 5
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Version(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// At 20 requests per second, requests are spaced by 50ms, give or take some scheduling slack
+	if len(arrivals) != n {
+		t.Fatalf("expected %d requests but got %d", n, len(arrivals))
+	}
+	for i := 1; i < n; i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < 40 * time.Millisecond {
+			t.Errorf("expected requests to be spaced by about 50ms but request %d came %s after the previous one", i, gap)
+		}
+	}
+
+	t.Run("context", func(t2 *testing.T) {
+		conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1), WithRateLimit(0.1, 1))
+		if err != nil {
+			t2.Fatal(err)
+		}
+		client := NewClient(conn)
+		if _, err := client.VersionContext(context.Background()); err != nil {
+			t2.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if _, err := client.VersionContext(ctx); err == nil {
+			t2.Error("expected the request to fail once its context is done while waiting for the rate limit")
+		}
+		if time.Since(start) > time.Second {
+			t2.Error("expected waiting for the rate limit to honor the context")
+		}
+	})
+}
+
+func TestWithAnonymous(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"version": 5}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"status": 401, "code": "AUTHORIZATION_REQUIRED", "message": "Authorization Required"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := Dial(WithApiUrl(srv.URL)); err == nil {
+		t.Fatal("expected dialing without credentials to fail unless it is anonymous")
+	}
+
+	conn, err := Dial(WithAnonymous(), WithApiUrl(srv.URL), WithRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(conn)
+
+	if _, err := client.Version(); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || queries[0] != "" {
+		t.Errorf("expected an anonymous request without an access token but got queries: %v", queries)
+	}
+
+	t.Run("credentialed", func(t2 *testing.T) {
+		if _, err := client.GetMyCredits(); !errors.As(err, &CredentialsErr{}) {
+			t2.Errorf("expected a CredentialsErr but got: %v", err)
+		}
+
+		client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+		if _, err := client.RunExperiment(context.Background(), testExpStr); !errors.As(err, &CredentialsErr{}) {
+			t2.Errorf("expected a CredentialsErr for a call the API refuses anonymously but got: %v", err)
+		}
+	})
+}
+
+func TestConn_Gzip(t *testing.T) {
+	gzipped := func(body string) []byte {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		zw.Write([]byte(body))
+		zw.Close()
+		return b.Bytes()
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected the request to accept gzip but got %q", r.Header.Get("Accept-Encoding"))
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		switch r.URL.Path {
+		case "/version":
+			w.Write(gzipped(`{"version": 5}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write(gzipped(`{"error": {"statusCode": 404, "name": "Error", "message": "Unknown \"Job\" id", "code": "MODEL_NOT_FOUND"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	testCases := map[string]DialOption{
+		"default_transport": WithTimeout(time.Minute),
+		"custom_transport": WithHTTPClient(&http.Client{Transport: &http.Transport{DisableCompression: true}}),
+	}
+
+	for name, option := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(1), option)
+			if err != nil {
+				t2.Fatal(err)
+			}
+			client := NewClient(conn)
+
+			version, err := client.Version()
+			if err != nil {
+				t2.Fatal(err)
+			}
+			if version != 5 {
+				t2.Errorf("expected version 5 from the gzip encoded body but got %v", version)
+			}
+
+			if _, err := client.GetJob("job-1"); !errors.As(err, &JobNotFoundErr{}) {
+				t2.Errorf("expected the gzip encoded error to be decoded but got: %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,14 @@
 package qiskit_api_go
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"net/http"
+	"regexp"
+)
 
 // httpErr is an internal error container that is returned sometimes by the IBM QX API
 type httpErr struct {
@@ -9,30 +17,340 @@ type httpErr struct {
 	Message 	string	`json:"message,omitempty"`
 	StatusCode 	int64	`json:"statusCode,omitempty"`
 	Code 	   	string	`json:"code,omitempty"`
+
+	// url is the URL of the failed request, with the access token redacted
+	url string
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+// The API returns the status fields either as numbers or as strings, e.g. "404", so both are accepted
+func (e *httpErr) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Name 	 	string			`json:"name,omitempty"`
+		Status 		json.RawMessage	`json:"status,omitempty"`
+		Message 	string			`json:"message,omitempty"`
+		StatusCode 	json.RawMessage	`json:"statusCode,omitempty"`
+		Code 	   	string			`json:"code,omitempty"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	status, err := decodeFlexInt(raw.Status)
+	if err != nil {
+		return err
+	}
+
+	statusCode, err := decodeFlexInt(raw.StatusCode)
+	if err != nil {
+		return err
+	}
+
+	*e = httpErr{Name: raw.Name, Status: status, Message: raw.Message, StatusCode: statusCode, Code: raw.Code}
+	return nil
+}
+
+// decodeFlexInt decodes an integer which may have been encoded as a string
+func decodeFlexInt(raw json.RawMessage) (int64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var i int64
+	if err := json.Unmarshal(raw, &i); err == nil {
+		return i, nil
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		return 0, err
+	}
+
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(str, 10, 64)
+}
+
+func (e *httpErr) Error() string {
+	msg := fmt.Sprintf("name: %s status: %d message: %s statusCode: %d code: %s", e.Name, e.Status, e.Message, e.StatusCode, e.Code)
+	if e.url != "" {
+		msg += fmt.Sprintf(" url: %s", e.url)
+	}
+	return msg
+}
+
+// URL returns the URL of the failed request, with the access token redacted, if the error came from a request
+func (e *httpErr) URL() string { return e.url }
+
+// notFound reports whether the error is the API reporting a missing resource
+func (e *httpErr) notFound() bool {
+	return e.StatusCode == http.StatusNotFound || e.Status == http.StatusNotFound
+}
+
+// decodeHttpErr decodes an API error from a response body, which is either the error itself or wrapped in an "error" field
+// nil is returned if the body isn't an API error
+func decodeHttpErr(b []byte) *httpErr {
+	var wrapped struct {
+		Err *httpErr	`json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(b, &wrapped); err == nil && wrapped.Err != nil {
+		return wrapped.Err
+	}
+
+	var e httpErr
+	if err := json.Unmarshal(b, &e); err != nil || e == (httpErr{}) {
+		return nil
+	}
+	return &e
 }
-func (e *httpErr) Error() string { return fmt.Sprintf("name: %s status: %d message: %s statusCode: %d code: %s", e.Name, e.Status, e.Message, e.StatusCode, e.Code) }
 
 type ApiErr struct {
 	usrMsg, devMsg string
+	url string
+	// Cause is the underlying error, e.g. a transport or API error, if any
+	Cause error
+}
+
+// NewApiErr returns an ApiErr with a message for users, a message for developers and the error which caused it
+func NewApiErr(usr, dev string, cause error) ApiErr {
+	return ApiErr{usrMsg: usr, devMsg: dev, Cause: cause}
+}
+
+func (e ApiErr) Error() string {
+	msg := fmt.Sprintf("usr_msg: %s dev_msg: %s", e.usrMsg, e.devMsg)
+	if e.url != "" {
+		msg += fmt.Sprintf(" url: %s", e.url)
+	}
+	if e.Cause != nil {
+		msg += fmt.Sprintf(" cause: %s", e.Cause)
+	}
+	return msg
 }
-func (e ApiErr) Error() string { return fmt.Sprintf("usr_msg: %s dev_msg: %s", e.usrMsg, e.devMsg) }
+
+// Unwrap returns the cause of the error, so it can be inspected with errors.Is and errors.As
+func (e ApiErr) Unwrap() error { return e.Cause }
+
+// URL returns the URL of the failed request, with the access token redacted, if the error came from a request
+func (e ApiErr) URL() string { return e.url }
 
 type BadBackendErr struct {
 	ApiErr
 	backend string
+	// available are the names of the available backends, when they are known
+	available []string
 }
 func (e BadBackendErr) Error() string {
 	e.usrMsg = fmt.Sprintf("could not find backend \"%s\" available", e.backend)
-	e.devMsg = fmt.Sprintf("backend \"%s\" does not exist. please use client.AvailableBackends to get options", e.backend)
+	if len(e.available) > 0 {
+		e.devMsg = fmt.Sprintf("backend \"%s\" does not exist. available backends are: %s", e.backend, strings.Join(e.available, ", "))
+	} else {
+		e.devMsg = fmt.Sprintf("backend \"%s\" does not exist. please use client.AvailableBackends to get options", e.backend)
+	}
 	return e.ApiErr.Error()
 }
 
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e BadBackendErr) Unwrap() error { return e.ApiErr }
+
 // CredentialsErr represents bad server credentials
 type CredentialsErr struct {
 	ApiErr
 }
 
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e CredentialsErr) Unwrap() error { return e.ApiErr }
+
 // RegisterSizeErr represents exceeding the maximum number of allowed qubits
+// When detected locally, it also records the offending register reference
+// When reported by the API, Max holds the maximum number of qubits of the backend
 type RegisterSizeErr struct {
 	ApiErr
-}
\ No newline at end of file
+	Register string
+	Index int
+	Size int
+	Max int
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e RegisterSizeErr) Unwrap() error { return e.ApiErr }
+
+var maxQubitErrRegex = regexp.MustCompile(`.*register exceed the number of qubits, it can't be greater than (\d+).*`)
+
+// registerSizeErr converts an API error about registers exceeding the number of qubits into a RegisterSizeErr
+// Any other error is returned as is
+func registerSizeErr(err error) error {
+	e, ok := err.(*httpErr)
+	if !ok {
+		return err
+	}
+
+	m := maxQubitErrRegex.FindStringSubmatch(e.Message)
+	if m == nil {
+		return err
+	}
+
+	max, convErr := strconv.Atoi(m[1])
+	if convErr != nil {
+		return err
+	}
+
+	return RegisterSizeErr{
+		ApiErr: NewApiErr(fmt.Sprintf("registers exceed the maximum number of qubits, %d", max), e.Message, e),
+		Max: max,
+	}
+}
+
+// InsufficientCreditsErr represents a job or experiment which costs more credits than the user has remaining
+type InsufficientCreditsErr struct {
+	ApiErr
+	// Required and Available are the credits the job needs and the credits remaining, when the API reported them
+	Required int
+	Available int
+}
+
+// insufficientCreditsCodes are the codes the API reports a lack of credits with
+var insufficientCreditsCodes = map[string]bool{
+	"NOT_CREDITS_AVALIABLES": true,
+	"NOT_CREDITS_AVAILABLES": true,
+	"NOT_ENOUGH_CREDITS": true,
+}
+
+var (
+	insufficientCreditsRegex = regexp.MustCompile(`(?i)(not|insufficient) (enough )?credits`)
+	requiredCreditsRegex = regexp.MustCompile(`(?i)(need|needs|require|requires|cost|costs) (\d+) credits?`)
+	availableCreditsRegex = regexp.MustCompile(`(?i)(have|has|only|remaining:?) (\d+)( credits?)?( remaining)?`)
+)
+
+// insufficientCreditsErr converts an API error about a lack of credits into an InsufficientCreditsErr
+// Any other error is returned as is
+func insufficientCreditsErr(err error) error {
+	e, ok := err.(*httpErr)
+	if !ok || (!insufficientCreditsCodes[e.Code] && !insufficientCreditsRegex.MatchString(e.Message)) {
+		return err
+	}
+
+	var creditsErr InsufficientCreditsErr
+	if m := requiredCreditsRegex.FindStringSubmatch(e.Message); m != nil {
+		creditsErr.Required, _ = strconv.Atoi(m[2])
+	}
+	if m := availableCreditsRegex.FindStringSubmatch(e.Message); m != nil {
+		creditsErr.Available, _ = strconv.Atoi(m[2])
+	}
+
+	usrMsg := "not enough credits to run the job"
+	if creditsErr.Required > 0 {
+		usrMsg = fmt.Sprintf("not enough credits to run the job, it needs %d credits but %d are available", creditsErr.Required, creditsErr.Available)
+	}
+	creditsErr.ApiErr = NewApiErr(usrMsg, e.Message, e)
+	return creditsErr
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e InsufficientCreditsErr) Unwrap() error { return e.ApiErr }
+
+// submitErr converts the API errors of submitting a job or experiment into their typed errors
+func submitErr(err error) error {
+	return insufficientCreditsErr(registerSizeErr(err))
+}
+
+// JobNotFoundErr represents a job which does not exist, or is not visible to the user
+type JobNotFoundErr struct {
+	ApiErr
+	JobId string
+}
+
+func (e JobNotFoundErr) Error() string {
+	e.usrMsg = fmt.Sprintf("could not find job \"%s\"", e.JobId)
+	e.devMsg = "the API returned 404 for the job. check the job id and that it belongs to your account"
+	return e.ApiErr.Error()
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e JobNotFoundErr) Unwrap() error { return e.ApiErr }
+
+// JobNotCancelledErr represents a job the API refused to cancel, e.g. because it already finished
+type JobNotCancelledErr struct {
+	ApiErr
+	JobId string
+	// Status is the status of the job after the cancel request, if the API reported it
+	Status JobStatus
+}
+
+func (e JobNotCancelledErr) Error() string {
+	e.usrMsg = fmt.Sprintf("could not cancel job \"%s\"", e.JobId)
+	if e.devMsg == "" {
+		e.devMsg = fmt.Sprintf("job status is %s instead of %s", e.Status, JobStatusCancelled)
+	}
+	return e.ApiErr.Error()
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e JobNotCancelledErr) Unwrap() error { return e.ApiErr }
+
+// JobTimeoutErr represents a job which did not finish within its Timeout
+type JobTimeoutErr struct {
+	ApiErr
+	JobId string
+	Timeout time.Duration
+}
+
+func (e JobTimeoutErr) Error() string {
+	e.usrMsg = fmt.Sprintf("job \"%s\" did not finish within %s", e.JobId, e.Timeout)
+	e.devMsg = "the job may still be running. increase the Job Timeout or keep polling it with GetJob"
+	return e.ApiErr.Error()
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e JobTimeoutErr) Unwrap() error { return e.ApiErr }
+
+// ExecutionErr represents an execution which ended in an error status on the backend
+type ExecutionErr struct {
+	ApiErr
+	ExecutionId string
+	// Status is the status the execution ended with, as reported by the API
+	Status string
+}
+
+func (e ExecutionErr) Error() string {
+	e.usrMsg = fmt.Sprintf("execution \"%s\" failed on the backend", e.ExecutionId)
+	e.devMsg = fmt.Sprintf("execution status is %s", e.Status)
+	return e.ApiErr.Error()
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e ExecutionErr) Unwrap() error { return e.ApiErr }
+
+// ExecutionTimeoutErr represents an execution which did not finish within the configured timeout
+type ExecutionTimeoutErr struct {
+	ApiErr
+	ExecutionId string
+	Timeout time.Duration
+}
+
+func (e ExecutionTimeoutErr) Error() string {
+	e.usrMsg = fmt.Sprintf("execution \"%s\" did not finish within %s", e.ExecutionId, e.Timeout)
+	e.devMsg = "the execution may still be running. increase the JobTimeout or keep waiting on it with WaitForExecution"
+	return e.ApiErr.Error()
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e ExecutionTimeoutErr) Unwrap() error { return e.ApiErr }
+
+// CodeImageErr represents an image of a code which could not be downloaded, or which is not a PNG
+type CodeImageErr struct {
+	ApiErr
+	CodeId string
+	// StatusCode and ContentType are those of the download response, if one was received
+	StatusCode int
+	ContentType string
+}
+
+func (e CodeImageErr) Error() string {
+	e.usrMsg = fmt.Sprintf("could not download the image of code \"%s\"", e.CodeId)
+	return e.ApiErr.Error()
+}
+
+// Unwrap returns the ApiErr of the error, which in turn unwraps to its cause
+func (e CodeImageErr) Unwrap() error { return e.ApiErr }
@@ -0,0 +1,182 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"io"
+	"time"
+)
+
+func TestHttpErr_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{name: "numbers", body: `{"error": {"name": "Error", "status": 404, "statusCode": 404, "message": "not found", "code": "MODEL_NOT_FOUND"}}`},
+		{name: "strings", body: `{"error": {"name": "Error", "status": "404", "statusCode": "404", "message": "not found", "code": "MODEL_NOT_FOUND"}}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var resp creditsResp
+			if err := json.Unmarshal([]byte(testCase.body), &resp); err != nil {
+				t2.Fatal(err)
+			}
+
+			if resp.Err == nil || resp.Err.Status != 404 || resp.Err.StatusCode != 404 || resp.Err.Code != "MODEL_NOT_FOUND" || resp.Err.Message != "not found" {
+				t2.Errorf("unexpected error: %+v", resp.Err)
+			}
+		})
+	}
+}
+
+func TestApiErr_Unwrap(t *testing.T) {
+	err := NewApiErr("request failed", "the connection was closed", io.ErrUnexpectedEOF)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the cause to be unwrapped from: %v", err)
+	}
+
+	wrapped := fmt.Errorf("running experiment: %w", BadBackendErr{ApiErr: NewApiErr("", "", io.ErrUnexpectedEOF), backend: "ibmqx42"})
+
+	var badBackendErr BadBackendErr
+	if !errors.As(wrapped, &badBackendErr) || badBackendErr.backend != "ibmqx42" {
+		t.Errorf("expected a BadBackendErr to be extracted from: %v", wrapped)
+	}
+
+	var apiErr ApiErr
+	if !errors.As(wrapped, &apiErr) {
+		t.Errorf("expected an ApiErr to be extracted from: %v", wrapped)
+	}
+	if !errors.Is(wrapped, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the cause to be unwrapped from: %v", wrapped)
+	}
+}
+
+func TestRegisterSizeErr_Unwrap(t *testing.T) {
+	cause := &httpErr{Status: 400, Message: "register exceed the number of qubits, it can't be greater than 5"}
+	err := registerSizeErr(cause)
+
+	var regErr RegisterSizeErr
+	if !errors.As(err, &regErr) || regErr.Max != 5 {
+		t.Fatalf("expected a RegisterSizeErr but got: %v", err)
+	}
+
+	var apiErr *httpErr
+	if !errors.As(err, &apiErr) || apiErr != cause {
+		t.Errorf("expected the API error to be unwrapped from: %v", err)
+	}
+}
+
+func TestCredentialsErr_Unwrap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"status": 401, "code": "LOGIN_FAILED", "message": "login failed"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := Dial(WithApiToken("bad-token"), WithApiUrl(srv.URL), WithRetries(1))
+
+	var credErr CredentialsErr
+	if !errors.As(err, &credErr) {
+		t.Fatalf("expected a CredentialsErr but got: %v", err)
+	}
+
+	var apiErr *httpErr
+	if !errors.As(err, &apiErr) || apiErr.Code != "LOGIN_FAILED" {
+		t.Errorf("expected the API error to be unwrapped from: %v", err)
+	}
+}
+
+func TestJobNotFoundErr_Unwrap(t *testing.T) {
+	wrapped := fmt.Errorf("getting job: %w", JobNotFoundErr{ApiErr: NewApiErr("", "", io.ErrUnexpectedEOF), JobId: "job-1"})
+
+	var notFound JobNotFoundErr
+	if !errors.As(wrapped, &notFound) || notFound.JobId != "job-1" {
+		t.Fatalf("expected a JobNotFoundErr but got: %v", wrapped)
+	}
+
+	var apiErr ApiErr
+	if !errors.As(wrapped, &apiErr) {
+		t.Errorf("expected an ApiErr to be extracted from: %v", wrapped)
+	}
+	if !errors.Is(wrapped, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the cause to be unwrapped from: %v", wrapped)
+	}
+}
+
+func TestJobNotCancelledErr_Unwrap(t *testing.T) {
+	wrapped := fmt.Errorf("cancelling job: %w", JobNotCancelledErr{ApiErr: NewApiErr("", "", io.ErrUnexpectedEOF), JobId: "job-2", Status: JobStatusCompleted})
+
+	var notCancelled JobNotCancelledErr
+	if !errors.As(wrapped, &notCancelled) || notCancelled.JobId != "job-2" || notCancelled.Status != JobStatusCompleted {
+		t.Fatalf("expected a JobNotCancelledErr but got: %v", wrapped)
+	}
+
+	var apiErr ApiErr
+	if !errors.As(wrapped, &apiErr) {
+		t.Errorf("expected an ApiErr to be extracted from: %v", wrapped)
+	}
+	if !errors.Is(wrapped, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the cause to be unwrapped from: %v", wrapped)
+	}
+}
+
+func TestJobTimeoutErr_Unwrap(t *testing.T) {
+	wrapped := fmt.Errorf("waiting for job: %w", JobTimeoutErr{ApiErr: NewApiErr("", "", io.ErrUnexpectedEOF), JobId: "job-3", Timeout: time.Minute})
+
+	var timeoutErr JobTimeoutErr
+	if !errors.As(wrapped, &timeoutErr) || timeoutErr.JobId != "job-3" || timeoutErr.Timeout != time.Minute {
+		t.Fatalf("expected a JobTimeoutErr but got: %v", wrapped)
+	}
+
+	var apiErr ApiErr
+	if !errors.As(wrapped, &apiErr) {
+		t.Errorf("expected an ApiErr to be extracted from: %v", wrapped)
+	}
+	if !errors.Is(wrapped, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the cause to be unwrapped from: %v", wrapped)
+	}
+}
+
+func TestTypedErrs_As(t *testing.T) {
+	cause := NewApiErr("", "", io.ErrUnexpectedEOF)
+
+	testCases := []struct {
+		name string
+		err error
+		target interface{}
+	}{
+		{name: "BadBackendErr", err: BadBackendErr{ApiErr: cause, backend: "ibmqx42"}, target: &BadBackendErr{}},
+		{name: "CredentialsErr", err: CredentialsErr{ApiErr: cause}, target: &CredentialsErr{}},
+		{name: "RegisterSizeErr", err: RegisterSizeErr{ApiErr: cause, Max: 5}, target: &RegisterSizeErr{}},
+		{name: "InsufficientCreditsErr", err: InsufficientCreditsErr{ApiErr: cause, Required: 3}, target: &InsufficientCreditsErr{}},
+		{name: "JobNotFoundErr", err: JobNotFoundErr{ApiErr: cause, JobId: "job-1"}, target: &JobNotFoundErr{}},
+		{name: "JobNotCancelledErr", err: JobNotCancelledErr{ApiErr: cause, JobId: "job-2"}, target: &JobNotCancelledErr{}},
+		{name: "JobTimeoutErr", err: JobTimeoutErr{ApiErr: cause, JobId: "job-3", Timeout: time.Minute}, target: &JobTimeoutErr{}},
+		{name: "ExecutionErr", err: ExecutionErr{ApiErr: cause, ExecutionId: "exec-1"}, target: &ExecutionErr{}},
+		{name: "ExecutionTimeoutErr", err: ExecutionTimeoutErr{ApiErr: cause, ExecutionId: "exec-2", Timeout: time.Minute}, target: &ExecutionTimeoutErr{}},
+		{name: "CodeImageErr", err: CodeImageErr{ApiErr: cause, CodeId: "code-1"}, target: &CodeImageErr{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			wrapped := fmt.Errorf("calling the API: %w", testCase.err)
+
+			if !errors.As(wrapped, testCase.target) {
+				t2.Errorf("expected a %s to be extracted from: %v", testCase.name, wrapped)
+			}
+
+			var apiErr ApiErr
+			if !errors.As(wrapped, &apiErr) {
+				t2.Errorf("expected an ApiErr to be extracted from: %v", wrapped)
+			}
+			if !errors.Is(wrapped, io.ErrUnexpectedEOF) {
+				t2.Errorf("expected the cause to be unwrapped from: %v", wrapped)
+			}
+		})
+	}
+}
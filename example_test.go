@@ -0,0 +1,31 @@
+package qiskit_api_go_test
+
+import (
+	"context"
+	"fmt"
+	qiskit "github.com/Zaba505/qiskit-api-go"
+)
+
+// Example only needs to compile; it guarantees the exported option model of the package,
+// i.e. DialOptions in conn.go and ClientOptions in client.go, stays usable from outside the package
+func Example() {
+	conn, err := qiskit.Dial(qiskit.WithApiToken("YOUR_API_TOKEN"), qiskit.WithRetries(qiskit.DefaultRetries), qiskit.WithTimeout(qiskit.DefaultTimeout))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	client := qiskit.NewClient(conn, qiskit.WithClientApplication(qiskit.DefaultClientAppl), qiskit.WithShots(1024))
+	id, err := client.RunExperiment(context.Background(), "OPENQASM 2.0;qreg q[1];creg c[1];x q[0];measure q[0] -> c[0];")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	result, err := client.GetResultFromExecution(id)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(result.Histogram())
+}
@@ -1,5 +1,9 @@
 module github.com/Zaba505/qiskit-api-go
 
-go 1.15
+go 1.20
 
-require github.com/sirupsen/logrus v1.7.0
+require golang.org/x/sync v0.0.0-20220907140024-f12130a52804
+
+require golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
+
+require golang.org/x/time v0.3.0
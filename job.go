@@ -2,28 +2,38 @@ package qiskit_api_go
 
 import (
 	"context"
+	"errors"
 	"time"
 	"fmt"
-	"github.com/sirupsen/logrus"
 	"sync"
 	"bytes"
 	"encoding/json"
 	"strings"
+	"crypto/sha256"
+	"net/url"
+	"io"
 )
 
-var jobLogger = logrus.New()
-
 const (
 	// DefaultBackend is the default backend for Jobs and Experiments to be run on
 	DefaultBackend = "simulator"
 	// DefaultShots is the default number of shots a Experiment/Job can be ran for
 	DefaultShots = 1
 	// DefaultNameFmt is default Experiment name format to be used unless specified otherwise
-	DefaultNameFmt = "Experiment #%d%d%d%d%d%d"
+	// It is formatted with the zero padded year, month, day, hour, minute and second the Experiment is ran at
+	DefaultNameFmt = "Experiment #%04d%02d%02d-%02d%02d%02d"
 	// MaxShots is the maximum shots a experiment can be ran for
 	MaxShots = 8192
 	// MaxTimeout is the maximum timeout allowed for waiting on an experiment result
 	MaxTimeout = 300 * time.Second
+	// MaxNameLength is the maximum length of an Experiment/Job name accepted by the API
+	MaxNameLength = 255
+	// DefaultPollInterval is the default interval between requests when polling for a result
+	DefaultPollInterval = 2 * time.Second
+	// DefaultMaxPollInterval is the default maximum interval between polls of WaitForJobBackoff
+	DefaultMaxPollInterval = time.Minute
+	// DefaultJobsLimit is the default number of jobs listed by GetJobs
+	DefaultJobsLimit = 50
 )
 
 // Job represents one or more QASM 2.0 Experiments
@@ -44,16 +54,133 @@ type Job struct {
 	MaxCredits int	`json:"maxCredits,omitempty"`
 	// Qasm is all the qasm code to be executed by this Job
 	Qasm []string	`json:"qasm,omitempty"`
+	// CodeId is the id of the Code the API saved for this Job when it was submitted
+	CodeId string	`json:"codeId,omitempty"`
+	// CreationDate is when the API created this Job, once it has been fetched
+	CreationDate string	`json:"creationDate,omitempty"`
+	// Results is the result of each circuit, as far as it has run, once the Job has been fetched
+	Results []ExpResult	`json:"results,omitempty"`
+	// Status is the last known status of the Job, set when it is submitted or fetched
+	Status JobStatus	`json:"status,omitempty"`
+
+	// requestedShots is the number of shots originally asked for, before any clamping
+	requestedShots int
+	// circuitQasms is the qasm the server stored for each circuit, once the Job has been fetched
+	circuitQasms []string
+	// creditsUsed is the credits the Job consumed, once it has completed
+	creditsUsed *float64
+	// circuitIndexes maps each circuit of Qasm to the index it was submitted as, when circuits were deduplicated
+	circuitIndexes []int
+	// queueInfo is where the Job last waited in the queue of its backend, if it was queued
+	queueInfo *QueueInfo
 }
 
 // NewJob returns a Job which is a composition of experiments and specifications of how they should be executed
 func NewJob(qasms []string, shots, maxCredits int) *Job {
-	if shots > MaxShots {
-		jobLogger.Warnf("shots were more than the maximum, %d, so they were set to be the maximum shots, %d", shots, MaxShots)
-		shots = MaxShots
+	clamped, _ := limitShots(shots, false, nopLogger{})
+	return &Job{Shots: clamped, MaxCredits: maxCredits, Qasm: qasms, requestedShots: shots}
+}
+
+// jobData is the wire format of a Job, it holds only the data fields of a Job
+type jobData struct {
+	Id string				`json:"id,omitempty"`
+	Name string				`json:"name,omitempty"`
+	Timeout time.Duration	`json:"timeout,omitempty"`
+	Shots int				`json:"shots,omitempty"`
+	MaxCredits int			`json:"maxCredits,omitempty"`
+	Qasm []string			`json:"qasm,omitempty"`
+	CodeId string			`json:"codeId,omitempty"`
+	CreationDate string		`json:"creationDate,omitempty"`
+	Results []ExpResult		`json:"results,omitempty"`
+	Status JobStatus		`json:"status,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface
+// Only the data fields of the Job are marshaled, while it is locked
+func (j *Job) MarshalJSON() ([]byte, error) {
+	j.mu.Lock()
+	data := jobData{
+		Id: j.Id,
+		Name: j.Name,
+		Timeout: j.Timeout,
+		Shots: j.Shots,
+		MaxCredits: j.MaxCredits,
+		Qasm: j.Qasm,
+		CodeId: j.CodeId,
+		CreationDate: j.CreationDate,
+		Results: j.Results,
+		Status: j.Status,
+	}
+	j.mu.Unlock()
+
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (j *Job) UnmarshalJSON(b []byte) error {
+	var data jobData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Id = data.Id
+	j.Name = data.Name
+	j.Timeout = data.Timeout
+	j.Shots = data.Shots
+	j.MaxCredits = data.MaxCredits
+	j.Qasm = data.Qasm
+	j.CodeId = data.CodeId
+	j.CreationDate = data.CreationDate
+	j.Results = data.Results
+	j.Status = data.Status
+	return nil
+}
+
+// validateSeed checks the seed fits in the 10 digits the API accepts, 0 meaning no seed
+func validateSeed(seed uint64) error {
+	if seed > MaxSeed {
+		return ApiErr{usrMsg: fmt.Sprintf("invalid seed (%d), seeds can have a maximum length of 10 digits", seed)}
+	}
+	return nil
+}
+
+// limitShots enforces MaxShots by clamping the shots to it, or by returning an error when strict is set
+func limitShots(shots int, strict bool, logger Logger) (int, error) {
+	if shots <= MaxShots {
+		return shots, nil
+	}
+
+	if strict {
+		return 0, ApiErr{usrMsg: fmt.Sprintf("shots (%d) exceed the maximum shots, %d", shots, MaxShots)}
+	}
+
+	logger.Warnf("shots were more than the maximum, %d, so they were set to be the maximum shots, %d", shots, MaxShots)
+	return MaxShots, nil
+}
+
+// limitOMP defaults the HPC omp number of threads and enforces its bounds by clamping it,
+// or by returning an error when strict is set
+func limitOMP(omp int, strict bool, logger Logger) (int, error) {
+	if omp == 0 {
+		return DefaultOMP, nil
+	}
+	if omp >= 1 && omp <= MaxOMP {
+		return omp, nil
 	}
 
-	return &Job{Shots: shots, MaxCredits: maxCredits, Qasm: qasms}
+	if strict {
+		return 0, ApiErr{usrMsg: fmt.Sprintf("omp (%d) must be between 1 and %d", omp, MaxOMP)}
+	}
+
+	clamped := MaxOMP
+	if omp < 1 {
+		clamped = 1
+	}
+	logger.Warnf("omp (%d) was out of range, so it was set to %d", omp, clamped)
+	return clamped, nil
 }
 
 // setId is a concurrent safe setter for the Jobs' Id
@@ -63,19 +190,209 @@ func (j *Job) setId(jobId string) {
 	j.Id = jobId
 }
 
-type jobExecReq struct {
-	Qasm string		`json:"qasm,omitempty"`
-	CodeType string	`json:"codeType,omitempty"`
-	Name string		`json:"name,omitempty"`
-	Qasms []string	`json:"qasms,omitempty"`
-	Shots float64	`json:"shots,omitempty"`
-	Bckend Backend	`json:"backend,omitempty"`
-	MaxCredit float64	`json:"maxCredit,omitempty"`
-	Seed int32	`json:"seed,omitempty"`
-	Hpc	struct {
-		MSO bool	`json:"multi_shot_optimization,omitempty"`
-		OMP int		`json:"omp_num_threads,omitempty"`
-	}	`json:"hpc,omitempty"`
+// setStatus is a concurrent safe setter for the Jobs' status
+func (j *Job) setStatus(status JobStatus) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Status = status
+}
+
+// defaultName returns the name of an Experiment ran at the given time, formatted with DefaultNameFmt
+func defaultName(t time.Time) string {
+	return fmt.Sprintf(DefaultNameFmt, t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
+
+// limitName enforces MaxNameLength by truncating the name with an ellipsis, or by returning an error when strict is set
+func limitName(name string, strict bool, logger Logger) (string, error) {
+	runes := []rune(name)
+	if len(runes) <= MaxNameLength {
+		return name, nil
+	}
+
+	if strict {
+		return "", ApiErr{usrMsg: fmt.Sprintf("name is %d characters long, the maximum is %d", len(runes), MaxNameLength)}
+	}
+
+	logger.Warnf("name was longer than the maximum, %d, so it was truncated", MaxNameLength)
+	return string(runes[:MaxNameLength-3]) + "...", nil
+}
+
+// CircuitQASMs returns the qasm the server stored for each circuit of the Job
+// This is only available once the Job has been fetched from the server, otherwise use Client.GetJobQASMs
+func (j *Job) CircuitQASMs() ([]string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.circuitQasms == nil {
+		return nil, ApiErr{usrMsg: "the circuit qasm of this job has not been fetched from the server", devMsg: "use client.GetJobQASMs to fetch it"}
+	}
+	return j.circuitQasms, nil
+}
+
+// CreditsConsumed returns the credits the Job actually consumed
+// false is returned when this is unavailable, e.g. the Job hasn't completed or ran on a simulator
+func (j *Job) CreditsConsumed() (float64, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.Status != JobStatusCompleted || j.creditsUsed == nil {
+		return 0, false
+	}
+	return *j.creditsUsed, true
+}
+
+// submitted is a concurrent safe setter for the Jobs' fields returned when it is submitted
+func (j *Job) submitted(r jobExecResp) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Id = r.Id
+	j.Status = ParseJobStatus(r.Status.Id)
+	j.CodeId = r.Code.Id
+	if j.CodeId == "" {
+		j.CodeId = r.Code.IdCode
+	}
+	if len(r.Qasms) > 0 {
+		j.Results = j.fanOutLocked(r.Qasms.results())
+	}
+	j.queueInfo = r.InfoQueue
+}
+
+// QueuePosition returns the position of the Job in the queue of its backend, as of when it was last submitted or fetched
+// false is returned if the Job was not queued then.
+func (j *Job) QueuePosition() (int, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.queueInfo == nil || j.queueInfo.Position <= 0 {
+		return 0, false
+	}
+	return j.queueInfo.Position, true
+}
+
+// dedupeCircuits returns the unique circuits of qasms, along with the index of each original circuit in the unique circuits
+func dedupeCircuits(qasms []string) (unique []string, indexes []int) {
+	seen := make(map[[sha256.Size]byte]int, len(qasms))
+	indexes = make([]int, len(qasms))
+	for i, qasm := range qasms {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(qasm)))
+		idx, exists := seen[sum]
+		if !exists {
+			idx = len(unique)
+			seen[sum] = idx
+			unique = append(unique, qasm)
+		}
+		indexes[i] = idx
+	}
+	return unique, indexes
+}
+
+// setCircuitIndexes is a concurrent safe setter for the Jobs' deduplicated circuit indexes
+func (j *Job) setCircuitIndexes(indexes []int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.circuitIndexes = indexes
+}
+
+// fanOut maps the results of the submitted circuits back to every original circuit of the Job
+// results is returned as is if the circuits were not deduplicated
+func (j *Job) fanOut(results []ExpResult) []ExpResult {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.fanOutLocked(results)
+}
+
+// fanOutLocked is fanOut for callers which already hold the Jobs' lock
+func (j *Job) fanOutLocked(results []ExpResult) []ExpResult {
+	if j.circuitIndexes == nil {
+		return results
+	}
+
+	fanned := make([]ExpResult, len(j.circuitIndexes))
+	for i, idx := range j.circuitIndexes {
+		if idx < len(results) {
+			fanned[i] = results[idx]
+		}
+	}
+	return fanned
+}
+
+// circuitCopies returns how many circuits of the Job were submitted as the given circuit
+func (j *Job) circuitCopies(submitted int) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.circuitIndexes == nil {
+		return 1
+	}
+
+	copies := 0
+	for _, idx := range j.circuitIndexes {
+		if idx == submitted {
+			copies++
+		}
+	}
+	return copies
+}
+
+// update is a concurrent safe setter for the Jobs' fields reported by the server
+func (j *Job) update(r jobResp) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Id = r.Id
+	j.Status = ParseJobStatus(r.Status)
+	j.circuitQasms = r.circuitQasms()
+	j.creditsUsed = r.CreditsUsed
+	j.Results = j.fanOutLocked(r.results())
+	j.queueInfo = r.InfoQueue
+	if r.Name != "" {
+		j.Name = r.Name
+	}
+	if r.Shots > 0 {
+		j.Shots = r.Shots
+	}
+	if r.MaxCredits > 0 {
+		j.MaxCredits = r.MaxCredits
+	}
+	if r.CreationDate != "" {
+		j.CreationDate = r.CreationDate
+	}
+	if len(j.Qasm) == 0 {
+		j.Qasm = j.circuitQasms
+	}
+}
+
+// JobRequest represents a job or experiment as it is submitted to the API
+type JobRequest struct {
+	Qasm string			`json:"qasm,omitempty"`
+	CodeType string		`json:"codeType,omitempty"`
+	Name string			`json:"name,omitempty"`
+	Qasms []JobQasm		`json:"qasms,omitempty"`
+	Shots int			`json:"shots,omitempty"`
+	Backend *JobBackend	`json:"backend,omitempty"`
+	MaxCredits int		`json:"maxCredits,omitempty"`
+	Seed uint64			`json:"seed,omitempty"`
+	Hpc *JobHPC			`json:"hpc,omitempty"`
+	Memory bool			`json:"memory,omitempty"`
+	NoiseModel *NoiseModel	`json:"noise_model,omitempty"`
+	QObject json.RawMessage	`json:"qObject,omitempty"`
+}
+
+// JobQasm is a single circuit of a JobRequest
+type JobQasm struct {
+	Qasm string	`json:"qasm,omitempty"`
+}
+
+// JobBackend is the backend a JobRequest is run on
+type JobBackend struct {
+	Name string	`json:"name,omitempty"`
+}
+
+// JobHPC is the HPC simulator configuration of a JobRequest
+type JobHPC struct {
+	MSO bool	`json:"multi_shot_optimization,omitempty"`
+	OMP int		`json:"omp_num_threads,omitempty"`
 }
 
 type jobExecResp struct {
@@ -88,8 +405,9 @@ type jobExecResp struct {
 	ModDate float64	`json:"modificationDate,omitempty"`
 	DeviceRunType string	`json:"deviceRunType,omitempty"`
 	Time float64	`json:"time,omitempty"`
+	StartDate string	`json:"startDate,omitempty"`
 	EndDate string	`json:"endDate,omitempty"`
-	InfoQueue interface{}	`json:"infoQueue,omitempty"`
+	InfoQueue *QueueInfo	`json:"infoQueue,omitempty"`
 
 	ParamsCustomize struct {
 		Seed float64	`json:"seed,omitempty"`
@@ -100,10 +418,95 @@ type jobExecResp struct {
 	}	`json:"status,omitempty"`
 
 	Result expResp	`json:"result,omitempty"`
+	// Qasms holds the circuits of a job with multiple experiments, along with their results
+	Qasms jobQasms	`json:"qasms,omitempty"`
 	Calib Calibration	`json:"calibration,omitempty"`
 	Code Code	`json:"code,omitempty"`
 }
 
+// QueueInfo represents where a job or execution waits in the queue of a backend
+type QueueInfo struct {
+	Status string				`json:"status,omitempty"`
+	Position int				`json:"position,omitempty"`
+	EstimatedStartTime string	`json:"estimatedStartTime,omitempty"`
+	EstimatedCompleteTime string	`json:"estimatedCompleteTime,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+// The API reports the position either as a number or as a string, and the estimated times either as dates or as
+// milliseconds since the epoch, which are converted to dates. Older responses report the queue status as a plain string.
+func (q *QueueInfo) UnmarshalJSON(b []byte) error {
+	var status string
+	if err := json.Unmarshal(b, &status); err == nil {
+		*q = QueueInfo{Status: status}
+		return nil
+	}
+
+	var raw struct {
+		Status string						`json:"status,omitempty"`
+		Position json.RawMessage			`json:"position,omitempty"`
+		EstimatedStartTime json.RawMessage	`json:"estimatedStartTime,omitempty"`
+		EstimatedCompleteTime json.RawMessage	`json:"estimatedCompleteTime,omitempty"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	position, err := decodeFlexInt(raw.Position)
+	if err != nil {
+		return err
+	}
+
+	start, err := decodeQueueTime(raw.EstimatedStartTime)
+	if err != nil {
+		return err
+	}
+
+	complete, err := decodeQueueTime(raw.EstimatedCompleteTime)
+	if err != nil {
+		return err
+	}
+
+	*q = QueueInfo{Status: raw.Status, Position: int(position), EstimatedStartTime: start, EstimatedCompleteTime: complete}
+	return nil
+}
+
+// decodeQueueTime decodes an estimated time of the queue, which is either a date or milliseconds since the epoch
+func decodeQueueTime(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var ms int64
+	if err := json.Unmarshal(raw, &ms); err == nil {
+		return time.UnixMilli(ms).UTC().Format("2006-01-02T15:04:05.000Z"), nil
+	}
+
+	var date string
+	err := json.Unmarshal(raw, &date)
+	return date, err
+}
+
+// expResult converts the execution response into the result format returned to users
+func (r jobExecResp) expResult() ExpResult {
+	res := r.Result.expResult(r.Status.Id, r.Id)
+	res.CodeId = r.Code.Id
+	res.InfoQueue = r.InfoQueue
+	return res
+}
+
+// expResult converts the raw experiment result into the result format returned to users
+func (r expResp) expResult(status, executionId string) ExpResult {
+	var res ExpResult
+	res.Status = status
+	res.Id = executionId
+	res.Result.ExtraInfo = r.Data.AdditionalData
+	res.Result.Measure = r.Data.P
+	res.Result.Bloch = r.Data.ValsXYZ
+	res.Memory = r.Data.Memory
+	return res
+}
+
 // expResp represents the result returned by an experiment
 type expResp struct {
 	Date string	`json:"date,omitempty"`
@@ -120,15 +523,18 @@ type expResp struct {
 		SerialNumDevice string	`json:"serialNumberDevice,omitempty"`
 		Time float64	`json:"time,omitempty"`
 		CregLabels string	`json:"creg_labels,omitempty"`
+		ValsXYZ BlochVectors	`json:"valsxyz,omitempty"`
+		Memory []string	`json:"memory,omitempty"`
 	}	`json:"data,omitempty"`
 }
 
 // ExpResult represents the result info to be returned by RunExperiment
 type ExpResult struct {
+	// Status is the status of the execution the result is of, the result is only complete once it is DONE or COMPLETED
 	Status string	`json:"status,omitempty"`
 	Id string	`json:"idExecution,omitempty"`
 	CodeId string	`json:"idCode,omitempty"`
-	InfoQueue interface{}	`json:"infoQueue,omitempty"`
+	InfoQueue *QueueInfo	`json:"infoQueue,omitempty"`
 	Result struct {
 		ExtraInfo struct {
 			Seed float64	`json:"seed,omitempty"`
@@ -138,67 +544,148 @@ type ExpResult struct {
 			Labels []string	`json:"labels,omitempty"`
 			Values []float64	`json:"values,omitempty"`
 		}	`json:"measure,omitempty"`
-		Bloch interface{}	`json:"bloch,omitempty"`
+		Bloch BlochVectors	`json:"bloch,omitempty"`
 	}	`json:"result,omitempty"`
+	// Memory is the measured outcome of every shot, if it was requested with WithMemory
+	Memory []string	`json:"memory,omitempty"`
+}
+
+// BlochVectors returns the Bloch vectors of the result, if the backend returned any
+func (r ExpResult) BlochVectors() []BlochVector {
+	return r.Result.Bloch
+}
+
+// BlochVector represents a qubit state on the Bloch sphere
+type BlochVector struct {
+	X float64	`json:"x"`
+	Y float64	`json:"y"`
+	Z float64	`json:"z"`
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface
+// Bloch vectors are accepted both as [x, y, z] triples and as {"x", "y", "z"} objects
+func (bv *BlochVector) UnmarshalJSON(b []byte) error {
+	var xyz []float64
+	if err := json.Unmarshal(b, &xyz); err == nil {
+		if len(xyz) != 3 {
+			return fmt.Errorf("invalid bloch vector of length %d", len(xyz))
+		}
+		*bv = BlochVector{X: xyz[0], Y: xyz[1], Z: xyz[2]}
+		return nil
+	}
+
+	type blochVector BlochVector
+	return json.Unmarshal(b, (*blochVector)(bv))
+}
+
+// BlochVectors is a list of Bloch vectors, one per qubit
+type BlochVectors []BlochVector
+
 // RunExperiment runs the given shit as an experiment
-func (c *Client) RunExperiment(ctx context.Context, qasm string, options ...ClientOption) error {
+// The id of the execution is returned, so its result can be retrieved with GetResultFromExecution
+func (c *Client) RunExperiment(ctx context.Context, qasm string, options ...ClientOption) (string, error) {
 	// Set options
-	for _, option := range options {
-		option(c.opts)
-	}
+	opts := c.callOptions(options...)
 
 	// Set defaults
-	if c.opts.backend == "" {
-		c.opts.backend = DefaultBackend
+	if opts.backend == "" {
+		opts.backend = opts.defaultBackend
 	}
-	if c.opts.name == "" {
-		now := time.Now()
-		c.opts.name = fmt.Sprintf(DefaultNameFmt, now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())
+	c.prefixName(&opts)
+	if opts.name == "" {
+		opts.name = defaultName(time.Now())
 	}
-	if c.opts.shots == 0 {
-		c.opts.shots = DefaultShots
+	if opts.shots == 0 {
+		opts.shots = DefaultShots
+	}
+	if opts.qasmVersion == "" {
+		opts.qasmVersion = DefaultQASMVersion
 	}
 
 	// Check for a seed value
-	if c.opts.seed > MaxSeed {
-		return ApiErr{usrMsg: fmt.Sprintf("invalid seed (%d), seeds can have a maximum length of 10 digits", c.opts.seed)}
+	if err := validateSeed(opts.seed); err != nil {
+		return "", err
+	}
+
+	// Check shots
+	shots, err := limitShots(opts.shots, opts.strict, c.conn.dopts.logger)
+	if err != nil {
+		return "", err
+	}
+
+	// Check name
+	name, err := limitName(opts.name, opts.strict, c.conn.dopts.logger)
+	if err != nil {
+		return "", err
+	}
+
+	// Check QASM version
+	codeType, err := qasmCodeType(opts.qasmVersion)
+	if err != nil {
+		return "", err
 	}
 
 	// Check backend
-	backendType := c.checkBackend(c.opts.backend, "experiment")
+	backendType := c.checkBackend(opts.backend, "experiment")
 	if backendType == "" {
-		return BadBackendErr{backend: c.opts.backend}
+		return "", BadBackendErr{backend: opts.backend}
+	}
+
+	// Validate QASM
+	if opts.validate {
+		if err := ValidateQASM(qasm); err != nil {
+			return "", err
+		}
 	}
 
 	// Tweak QASM
-	qasm = strings.Replace(qasm, "IBMQASM 2.0;", "", -1)
-	qasm = strings.Replace(qasm, "OPENQASM 2.0;", "", -1)
+	qasm = stripQasmHeaders(qasm, opts.qasmVersion)
+
+	// Create request and let the submit hook inspect it
+	req := &JobRequest{
+		Name: name,
+		Qasm: qasm,
+		CodeType: codeType,
+		Shots: shots,
+		Seed: opts.seed,
+		Backend: &JobBackend{Name: backendType},
+		Memory: opts.memory,
+	}
+	if opts.noiseModel != nil {
+		if err := c.checkNoiseModel(*opts.noiseModel, backendType, opts.backend, qasm); err != nil {
+			return "", err
+		}
+		req.NoiseModel = opts.noiseModel
+	}
+	if opts.hpc {
+		omp, err := limitOMP(opts.omp, opts.strict, c.conn.dopts.logger)
+		if err != nil {
+			return "", err
+		}
+		req.Hpc = &JobHPC{MSO: opts.mso, OMP: omp}
+	}
+	if err := runSubmitHook(opts, req); err != nil {
+		return "", err
+	}
 
 	// Construct parameters for the request
 	var params string
-	if c.opts.seed > 0 {
-		params = fmt.Sprintf("&shots=%d&seed=%d&deviceRunType=%s", c.opts.shots, c.opts.seed, backendType)
+	if req.Seed > 0 {
+		params = fmt.Sprintf("&shots=%d&seed=%d&deviceRunType=%s", req.Shots, req.Seed, req.Backend.Name)
 	} else {
-		params = fmt.Sprintf("&shots=%d&deviceRunType=%s", c.opts.shots, backendType)
+		params = fmt.Sprintf("&shots=%d&deviceRunType=%s", req.Shots, req.Backend.Name)
 	}
 
-	// Create request body and send it
+	// Create request body and send it, the rest of the request is sent as parameters
 	var b bytes.Buffer
-	req := &jobExecReq{
-		Name: c.opts.name,
-		Qasm: qasm,
-		CodeType: "QASM2",
-	}
-	err := json.NewEncoder(&b).Encode(req)
+	err = json.NewEncoder(&b).Encode(&JobRequest{Name: req.Name, Qasm: req.Qasm, CodeType: req.CodeType, Memory: req.Memory, NoiseModel: req.NoiseModel, Hpc: req.Hpc})
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	resp, err := c.conn.post("codes/execute", params, &b)
+	resp, err := c.conn.postCtx(ctx, "codes/execute", params, &b)
 	if err != nil {
-		return err
+		return "", submitErr(err)
 	}
 	defer resp.Body.Close()
 
@@ -206,45 +693,1016 @@ func (c *Client) RunExperiment(ctx context.Context, qasm string, options ...Clie
 	var i jobExecResp
 	err = c.conn.decode(resp.Body, &i)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if i.Err != nil {
-		return i.Err
+		return "", submitErr(i.Err)
 	}
 
-	return nil
+	c.invalidateCredits()
+	return i.Id, nil
+}
+
+// RunExperimentAndWait runs the experiment, polls its execution on the given interval until it is done and returns its result
+// The polling interval configured by WithPollInterval is used if poll isn't positive.
+// If a timeout is configured with JobTimeout, an ExecutionTimeoutErr is returned once the execution has been waited on for that long.
+// Otherwise, the execution is waited on until ctx is done.
+func (c *Client) RunExperimentAndWait(ctx context.Context, qasm string, poll time.Duration, options ...ClientOption) (ExpResult, error) {
+	opts := c.callOptions(options...)
+	if poll <= 0 {
+		poll = opts.pollInterval
+	}
+
+	executionId, err := c.RunExperiment(ctx, qasm, options...)
+	if err != nil {
+		return ExpResult{}, err
+	}
+	return c.waitForExecution(ctx, executionId, poll, opts.timeout)
+}
+
+// WaitForExecution polls the execution on the given interval until it is done, and returns its result
+// The polling interval configured by WithPollInterval is used if interval isn't positive.
+// An ExecutionErr is returned if the execution failed on the backend. The execution is waited on until ctx is done.
+func (c *Client) WaitForExecution(ctx context.Context, executionId string, interval time.Duration) (ExpResult, error) {
+	if interval <= 0 {
+		interval = c.callOptions().pollInterval
+	}
+	return c.waitForExecution(ctx, executionId, interval, 0)
 }
 
-// RunJob runs the given job on the specified backend
+// waitForExecution polls the execution until it is done, giving up with an ExecutionTimeoutErr after timeout, if it is positive
+func (c *Client) waitForExecution(ctx context.Context, executionId string, interval, timeout time.Duration) (ExpResult, error) {
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+
+	for {
+		i, err := c.fetchExecution(ctx, executionId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ExpResult{}, ctx.Err()
+			}
+			return ExpResult{}, err
+		}
+
+		status := executionStatus(i.Status.Id)
+		if status.IsTerminal() {
+			if status != JobStatusCompleted {
+				return ExpResult{}, ExecutionErr{ExecutionId: executionId, Status: i.Status.Id}
+			}
+			return c.transformResult(i.expResult())
+		}
+
+		select {
+		case <-ctx.Done():
+			return ExpResult{}, ctx.Err()
+		case <-timedOut:
+			return ExpResult{}, ExecutionTimeoutErr{ExecutionId: executionId, Timeout: timeout}
+		case <-c.after(interval):
+		}
+	}
+}
+
+// executionStatus parses the status of an execution, which reports DONE once it completed
+func executionStatus(status string) JobStatus {
+	if strings.EqualFold(status, "DONE") {
+		return JobStatusCompleted
+	}
+	return ParseJobStatus(status)
+}
+
+// RunJob submits the given job to the specified backend
+// Once submitted, the job Id is set and the job is cached by the client
 func (c *Client) RunJob(ctx context.Context, j *Job, options ...ClientOption) error {
 	// Set options
-	for _, option := range options {
-		option(c.opts)
-	}
+	opts := c.callOptions(options...)
 
 	// Set defaults
-	if c.opts.backend == "" {
-		WithBackend(DefaultBackend)(c.opts)
+	if opts.backend == "" {
+		opts.backend = opts.defaultBackend
 	}
-	if c.opts.shots == 0 {
-		WithShots(DefaultShots)(c.opts)
+	if opts.shots == 0 {
+		opts.shots = DefaultShots
 	}
+	c.prefixName(&opts)
 
 	// Check for a seed value
-	if c.opts.seed > MaxSeed {
-		return ApiErr{usrMsg: fmt.Sprintf("invalid seed (%d), seeds can have a maximum length of 10 digits", c.opts.seed)}
+	if err := validateSeed(opts.seed); err != nil {
+		return err
+	}
+
+	// Read the job once, since it may be read or updated concurrently, e.g. by GetJob
+	j.mu.Lock()
+	shots, requestedShots, maxCredits, jobQasms := j.Shots, j.requestedShots, j.MaxCredits, j.Qasm
+	j.mu.Unlock()
+
+	// Check shots, using the originally requested shots if NewJob clamped them, or the configured shots if the job has none
+	if shots == MaxShots && requestedShots > MaxShots {
+		shots = requestedShots
+	}
+	if shots == 0 {
+		shots = opts.shots
+	}
+	shots, err := limitShots(shots, opts.strict, c.conn.dopts.logger)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	j.Shots = shots
+	j.mu.Unlock()
+
+	// Check name
+	name, err := limitName(opts.name, opts.strict, c.conn.dopts.logger)
+	if err != nil {
+		return err
 	}
 
 	// Check backend
-	backendType := c.checkBackend(c.opts.backend, "job")
+	backendType := c.checkBackend(opts.backend, "job")
 	if backendType == "" {
-		return BadBackendErr{backend: c.opts.backend}
+		return BadBackendErr{backend: opts.backend}
+	}
+
+	// Validate QASM
+	if opts.validate {
+		for _, qasm := range jobQasms {
+			if err := ValidateQASM(qasm); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Create request and let the submit hook inspect it
+	req := &JobRequest{
+		Name: name,
+		Shots: shots,
+		MaxCredits: maxCredits,
+		Seed: opts.seed,
+		Backend: &JobBackend{Name: backendType},
+		Memory: opts.memory,
+	}
+	qasms := jobQasms
+	if opts.dedupe {
+		var indexes []int
+		qasms, indexes = dedupeCircuits(jobQasms)
+		j.setCircuitIndexes(indexes)
+	}
+	for _, qasm := range qasms {
+		req.Qasms = append(req.Qasms, JobQasm{Qasm: qasm})
+	}
+	if opts.noiseModel != nil {
+		if err := c.checkNoiseModel(*opts.noiseModel, backendType, opts.backend, qasms...); err != nil {
+			return err
+		}
+		req.NoiseModel = opts.noiseModel
 	}
+	if opts.hpc {
+		omp, err := limitOMP(opts.omp, opts.strict, c.conn.dopts.logger)
+		if err != nil {
+			return err
+		}
+		req.Hpc = &JobHPC{MSO: opts.mso, OMP: omp}
+	}
+	if err := runSubmitHook(opts, req); err != nil {
+		return err
+	}
+
+	// Create request body and send it
+	var b bytes.Buffer
+	err = json.NewEncoder(&b).Encode(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.conn.postCtx(ctx, "Jobs", "", &b)
+	if err != nil {
+		return submitErr(err)
+	}
+	defer resp.Body.Close()
+
+	// Handle response body
+	var i jobExecResp
+	err = c.conn.decode(resp.Body, &i)
+	if err != nil {
+		return err
+	}
+
+	if i.Err != nil {
+		return submitErr(i.Err)
+	}
+
+	j.submitted(i)
 
+	c.mu.Lock()
+	c.jobs[i.Id] = j
+	c.mu.Unlock()
+
+	c.invalidateCredits()
 	return nil
 }
 
-func (c *Client) GetJob(jobId string) {}
-func (c *Client) GetJobs(jobIds ...string) {}
-func (c *Client) CancelJob(jobId string) {}
+// QObjCodeType is the code type of jobs submitted as a QObj
+const QObjCodeType = "QOBJ"
+
+// RunQObj submits a compiled QObj to the specified backend and returns the id of the job
+// The QObj is sent as is, so its shots, seed and other run configuration are taken from it rather than from the client options
+func (c *Client) RunQObj(ctx context.Context, qobj json.RawMessage, options ...ClientOption) (string, error) {
+	// Set options
+	opts := c.callOptions(options...)
+
+	// Set defaults
+	if opts.backend == "" {
+		opts.backend = opts.defaultBackend
+	}
+	c.prefixName(&opts)
+
+	// Check QObj
+	if !json.Valid(qobj) {
+		return "", ApiErr{usrMsg: "invalid QObj, it is not valid JSON"}
+	}
+
+	// Check name
+	name, err := limitName(opts.name, opts.strict, c.conn.dopts.logger)
+	if err != nil {
+		return "", err
+	}
+
+	// Check backend
+	backendType := c.checkBackend(opts.backend, "job")
+	if backendType == "" {
+		return "", BadBackendErr{backend: opts.backend}
+	}
+
+	// Create request and let the submit hook inspect it
+	req := &JobRequest{
+		Name: name,
+		CodeType: QObjCodeType,
+		MaxCredits: opts.maxCredits,
+		Backend: &JobBackend{Name: backendType},
+		QObject: qobj,
+	}
+	if err := runSubmitHook(opts, req); err != nil {
+		return "", err
+	}
+
+	// Create request body and send it
+	var b bytes.Buffer
+	err = json.NewEncoder(&b).Encode(req)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.conn.postCtx(ctx, "Jobs", "", &b)
+	if err != nil {
+		return "", submitErr(err)
+	}
+	defer resp.Body.Close()
+
+	// Handle response body
+	var i jobExecResp
+	err = c.conn.decode(resp.Body, &i)
+	if err != nil {
+		return "", err
+	}
+
+	if i.Err != nil {
+		return "", submitErr(i.Err)
+	}
+
+	c.invalidateCredits()
+	return i.Id, nil
+}
+
+const (
+	// creditsPerRun is the approximate credits a single run of an experiment on a real device costs
+	creditsPerRun = 3
+	// shotsPerRun is the approximate number of shots a single run of an experiment covers, more shots take more runs
+	shotsPerRun = 1024
+)
+
+// EstimateCost approximates the credits running the job on the given backend costs, using the default backend if none is given
+// The API doesn't publish how it charges credits, so the estimate is a heuristic: simulators are treated as free,
+// while real devices are assumed to cost 3 credits for every 1024 shots, or part thereof, of every experiment.
+// The API may charge differently. If the estimate exceeds the credits remaining, it is returned along with an InsufficientCreditsErr.
+func (c *Client) EstimateCost(j *Job, backend string) (int, error) {
+	if backend == "" {
+		backend = c.callOptions().defaultBackend
+	}
+
+	// The default backend is an alias, e.g. "simulator", so the name is resolved rather than matched
+	b, err := c.ResolveBackend(context.Background(), backend)
+	if err != nil {
+		return 0, err
+	}
+
+	j.mu.Lock()
+	shots, experiments := j.Shots, len(j.Qasm)
+	j.mu.Unlock()
+
+	cost := estimateCost(shots, experiments, b.Simulator)
+	if cost == 0 {
+		return 0, nil
+	}
+
+	credits, err := c.GetMyCreditsCached()
+	if err != nil {
+		return cost, err
+	}
+	if float64(cost) > credits.Remaining {
+		available := int(credits.Remaining)
+		return cost, InsufficientCreditsErr{
+			ApiErr: ApiErr{usrMsg: fmt.Sprintf("not enough credits to run the job, it needs %d credits but %d are available", cost, available)},
+			Required: cost,
+			Available: available,
+		}
+	}
+	return cost, nil
+}
+
+// estimateCost approximates the credits running the given number of experiments for the given shots costs
+func estimateCost(shots, experiments int, simulator bool) int {
+	if simulator || experiments == 0 {
+		return 0
+	}
+	if shots <= 0 {
+		shots = DefaultShots
+	}
+
+	runs := (shots + shotsPerRun - 1) / shotsPerRun
+	return runs * creditsPerRun * experiments
+}
+
+// RunJobs submits the given jobs concurrently, with at most concurrency submissions in flight at once
+// The errors of the jobs which failed to be submitted are combined into the returned error
+// Once ctx is done, the jobs which haven't been submitted yet fail with its error
+func (c *Client) RunJobs(ctx context.Context, jobs []*Job, concurrency int, options ...ClientOption) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	// Number the jobs in order, rather than in the order they happen to be submitted
+	var names []string
+	if opts := c.callOptions(options...); opts.name == "" && opts.namePrefix != "" {
+		seq := c.reserveNames(opts.namePrefix, len(jobs))
+		for i := range jobs {
+			names = append(names, fmt.Sprintf("%s-%d", opts.namePrefix, seq + i + 1))
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	for i, j := range jobs {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			errs[i] = fmt.Errorf("job %d: %w", i, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, j *Job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			jobOptions := options
+			if names != nil {
+				jobOptions = append(options[:len(options):len(options)], WithName(names[i]))
+			}
+			if err := c.RunJob(ctx, j, jobOptions...); err != nil {
+				errs[i] = fmt.Errorf("job %d: %w", i, err)
+			}
+		}(i, j)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// prefixName names the job with the configured name prefix and the next number, unless it is named already
+func (c *Client) prefixName(opts *clientOptions) {
+	if opts.name != "" || opts.namePrefix == "" {
+		return
+	}
+	opts.name = fmt.Sprintf("%s-%d", opts.namePrefix, c.reserveNames(opts.namePrefix, 1) + 1)
+}
+
+// reserveNames reserves the next n numbers of the name prefix, returning the last number used before them
+func (c *Client) reserveNames(prefix string, n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seq := c.nameSeqs[prefix]
+	c.nameSeqs[prefix] += n
+	return seq
+}
+
+// checkNoiseModel checks that the backend is a simulator and that the noise model is valid for the circuits
+func (c *Client) checkNoiseModel(nm NoiseModel, backendType, backend string, qasms ...string) error {
+	c.mu.Lock()
+	b := c.backends[backend]
+	c.mu.Unlock()
+	if backendType != "sim_trivial_2" && (b == nil || !b.Simulator) {
+		return ApiErr{usrMsg: fmt.Sprintf("noise models can only be used with simulators, backend \"%s\" is not one", backend)}
+	}
+
+	return nm.validate(qasms...)
+}
+
+// runSubmitHook passes the request to the configured submit hook, if any
+// The request must still target a backend afterwards
+func runSubmitHook(opts clientOptions, req *JobRequest) error {
+	if opts.submitHook == nil {
+		return nil
+	}
+
+	if err := opts.submitHook(req); err != nil {
+		return err
+	}
+
+	if req.Backend == nil || req.Backend.Name == "" {
+		return ApiErr{usrMsg: "submit hook removed the backend from the request"}
+	}
+	return nil
+}
+
+// JobStatus represents the status of a Job as reported by the API
+type JobStatus string
+
+// These are the statuses a Job moves through
+const (
+	JobStatusCreating JobStatus = "CREATING"
+	JobStatusValidating JobStatus = "VALIDATING"
+	JobStatusQueued JobStatus = "QUEUED"
+	JobStatusRunning JobStatus = "RUNNING"
+	JobStatusCompleted JobStatus = "COMPLETED"
+	JobStatusCancelled JobStatus = "CANCELLED"
+	JobStatusErrorCreating JobStatus = "ERROR_CREATING_JOB"
+	JobStatusErrorValidating JobStatus = "ERROR_VALIDATING_JOB"
+	JobStatusErrorRunning JobStatus = "ERROR_RUNNING_JOB"
+	// JobStatusError is a generic error status, as reported for executions
+	JobStatusError JobStatus = "ERROR"
+	// JobStatusUnknown is any status this client doesn't know of
+	JobStatusUnknown JobStatus = "UNKNOWN"
+)
+
+// ParseJobStatus parses a status reported by the API, regardless of its case
+// JobStatusUnknown is returned for unknown statuses
+func ParseJobStatus(status string) JobStatus {
+	s := JobStatus(strings.ToUpper(strings.TrimSpace(status)))
+	switch s {
+	case JobStatusCreating, JobStatusValidating, JobStatusQueued, JobStatusRunning, JobStatusCompleted, JobStatusCancelled,
+		JobStatusErrorCreating, JobStatusErrorValidating, JobStatusErrorRunning, JobStatusError:
+		return s
+	}
+	return JobStatusUnknown
+}
+
+// IsTerminal reports whether a job in the status will no longer change
+func (s JobStatus) IsTerminal() bool {
+	switch s {
+	case JobStatusCompleted, JobStatusCancelled:
+		return true
+	}
+	return s.IsError()
+}
+
+// IsError reports whether the status is one of the error statuses
+func (s JobStatus) IsError() bool {
+	switch s {
+	case JobStatusErrorCreating, JobStatusErrorValidating, JobStatusErrorRunning, JobStatusError:
+		return true
+	}
+	return false
+}
+
+type jobStatusResp struct {
+	Err *httpErr	`json:"error,omitempty"`
+	Status string	`json:"status,omitempty"`
+}
+
+// GetJobStatus retrieves only the status of a job, which is cheaper than retrieving the whole job
+func (c *Client) GetJobStatus(ctx context.Context, jobId string) (JobStatus, error) {
+	resp, err := c.conn.getCtx(ctx, fmt.Sprintf("Jobs/%s/status", jobId), "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var i jobStatusResp
+	err = c.conn.decode(resp.Body, &i)
+	if err != nil {
+		return "", err
+	}
+
+	if i.Err != nil {
+		return "", i.Err
+	}
+
+	return ParseJobStatus(i.Status), nil
+}
+
+// jobResp represents a Job as returned by the Jobs endpoint
+type jobResp struct {
+	Err *httpErr	`json:"error,omitempty"`
+
+	Id string		`json:"id,omitempty"`
+	Name string		`json:"name,omitempty"`
+	Status string	`json:"status,omitempty"`
+	Shots int		`json:"shots,omitempty"`
+	MaxCredits int	`json:"maxCredits,omitempty"`
+	CreationDate string	`json:"creationDate,omitempty"`
+	CreditsUsed *float64	`json:"creditsUsed,omitempty"`
+	InfoQueue *QueueInfo	`json:"infoQueue,omitempty"`
+	Qasms jobQasms	`json:"qasms,omitempty"`
+}
+
+// jobQasms represents the circuits of a job, as returned by the Jobs endpoint
+type jobQasms []struct {
+	Qasm string			`json:"qasm,omitempty"`
+	Status string		`json:"status,omitempty"`
+	ExecutionId string	`json:"executionId,omitempty"`
+	Result expResp		`json:"result,omitempty"`
+}
+
+// results returns the result of each circuit, as far as the circuit has run
+func (qs jobQasms) results() []ExpResult {
+	results := make([]ExpResult, len(qs))
+	for i, q := range qs {
+		results[i] = q.Result.expResult(q.Status, q.ExecutionId)
+	}
+	return results
+}
+
+// circuitQasms returns the qasm stored for each circuit of the job
+func (r jobResp) circuitQasms() []string {
+	qasms := make([]string, len(r.Qasms))
+	for i, q := range r.Qasms {
+		qasms[i] = q.Qasm
+	}
+	return qasms
+}
+
+// results returns the result of each circuit of the job, as far as the circuit has run
+func (r jobResp) results() []ExpResult {
+	return r.Qasms.results()
+}
+
+// fetchJob retrieves a job from the Jobs endpoint
+func (c *Client) fetchJob(ctx context.Context, jobId string) (jobResp, error) {
+	resp, err := c.conn.getCtx(ctx, fmt.Sprintf("Jobs/%s", jobId), "")
+	if err != nil {
+		if httpErr, ok := err.(*httpErr); ok && httpErr.notFound() {
+			return jobResp{}, JobNotFoundErr{JobId: jobId}
+		}
+		return jobResp{}, err
+	}
+	defer resp.Body.Close()
+
+	var i jobResp
+	err = c.conn.decode(resp.Body, &i)
+	if err != nil {
+		return jobResp{}, err
+	}
+
+	if i.Err != nil {
+		if i.Err.notFound() {
+			return jobResp{}, JobNotFoundErr{JobId: jobId}
+		}
+		return jobResp{}, i.Err
+	}
+	return i, nil
+}
+
+// GetJobQASMs retrieves the qasm the server stored for each circuit of a job
+func (c *Client) GetJobQASMs(ctx context.Context, jobId string) ([]string, error) {
+	r, err := c.fetchJob(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	j, cached := c.jobs[jobId]
+	c.mu.Unlock()
+	if cached {
+		j.update(r)
+	}
+
+	return r.circuitQasms(), nil
+}
+
+// GetJob retrieves a job, along with the qasm and result of each of its circuits
+// The job is cached by the client, so a job submitted with RunJob is updated in place.
+// A JobNotFoundErr is returned if the job does not exist.
+func (c *Client) GetJob(jobId string) (*Job, error) {
+	return c.getJob(context.Background(), jobId)
+}
+
+func (c *Client) getJob(ctx context.Context, jobId string) (*Job, error) {
+	r, err := c.fetchJob(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	return c.cacheJob(r), nil
+}
+
+// JobQueuePosition retrieves the position of a job in the queue of its backend
+// 0 is returned once the job is no longer queued
+func (c *Client) JobQueuePosition(jobId string) (int, error) {
+	r, err := c.fetchJob(context.Background(), jobId)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.InfoQueue == nil {
+		return 0, nil
+	}
+	return r.InfoQueue.Position, nil
+}
+
+// cacheJob updates the cached job with the job returned by the API, caching it first if it is unknown
+func (c *Client) cacheJob(r jobResp) *Job {
+	c.mu.Lock()
+	j, cached := c.jobs[r.Id]
+	if !cached {
+		j = &Job{}
+		c.jobs[r.Id] = j
+	}
+	c.mu.Unlock()
+
+	j.update(r)
+	return j
+}
+
+// GetJobs retrieves the jobs with the given ids
+// If no ids are given, the most recent jobs of the user are listed instead, see GetJobsWithQuery.
+func (c *Client) GetJobs(jobIds ...string) ([]*Job, error) {
+	if len(jobIds) == 0 {
+		return c.GetJobsWithQuery(JobQuery{})
+	}
+
+	jobs := make([]*Job, 0, len(jobIds))
+	for _, jobId := range jobIds {
+		j, err := c.GetJob(jobId)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// JobQuery filters and paginates the jobs listed by GetJobsWithQuery
+type JobQuery struct {
+	// Limit is the maximum number of jobs listed, DefaultJobsLimit is used if it isn't set
+	Limit int
+	// Skip is the number of jobs to skip, e.g. to list the next page of jobs
+	Skip int
+	// Status only lists jobs in the given status
+	Status JobStatus
+	// Backend only lists jobs run on the given backend
+	Backend string
+}
+
+// filter returns the query as a filter for the Jobs endpoint, listing the most recent jobs first
+func (q JobQuery) filter() (string, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultJobsLimit
+	}
+
+	where := make(map[string]string)
+	if q.Status != "" {
+		where["status"] = string(q.Status)
+	}
+	if q.Backend != "" {
+		where["backend.name"] = q.Backend
+	}
+
+	b, err := json.Marshal(struct {
+		Order string	`json:"order"`
+		Limit int		`json:"limit"`
+		Skip int		`json:"skip,omitempty"`
+		Where map[string]string	`json:"where,omitempty"`
+	}{Order: "creationDate DESC", Limit: limit, Skip: q.Skip, Where: where})
+	return string(b), err
+}
+
+// GetJobsWithQuery lists the jobs of the user matching the query, most recent first
+func (c *Client) GetJobsWithQuery(q JobQuery) ([]*Job, error) {
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.conn.get("Jobs", "&filter=" + url.QueryEscape(filter))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rs []jobResp
+	err = c.conn.decodeChecked(resp.Body, &rs)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, len(rs))
+	for i, r := range rs {
+		jobs[i] = c.cacheJob(r)
+	}
+	return jobs, nil
+}
+
+// CancelJob cancels a job which has not finished yet, removing it from the client cache once it is cancelled
+// A JobNotCancelledErr is returned if the API refuses to cancel the job, e.g. because it already completed.
+func (c *Client) CancelJob(jobId string) error {
+	return c.cancelJob(context.Background(), jobId)
+}
+
+func (c *Client) cancelJob(ctx context.Context, jobId string) error {
+	resp, err := c.conn.postCtx(ctx, fmt.Sprintf("Jobs/%s/cancel", jobId), "", nil)
+	if httpErr, ok := err.(*httpErr); ok {
+		if httpErr.notFound() {
+			return JobNotFoundErr{JobId: jobId}
+		}
+		return JobNotCancelledErr{ApiErr: ApiErr{devMsg: httpErr.Error()}, JobId: jobId}
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var i jobResp
+	err = c.conn.decode(resp.Body, &i)
+	if err != nil {
+		return err
+	}
+
+	if i.Err != nil {
+		return JobNotCancelledErr{ApiErr: ApiErr{devMsg: i.Err.Error(), url: redactUrl(resp.Request.URL)}, JobId: jobId}
+	}
+	if status := ParseJobStatus(i.Status); status != JobStatusCancelled {
+		return JobNotCancelledErr{ApiErr: ApiErr{url: redactUrl(resp.Request.URL)}, JobId: jobId, Status: status}
+	}
+
+	c.mu.Lock()
+	j, cached := c.jobs[jobId]
+	delete(c.jobs, jobId)
+	c.mu.Unlock()
+	if cached {
+		j.setStatus(JobStatusCancelled)
+	}
+	return nil
+}
+
+// activeJobStatuses are the statuses of jobs which have not finished yet
+var activeJobStatuses = []JobStatus{JobStatusCreating, JobStatusValidating, JobStatusQueued, JobStatusRunning}
+
+// cancelConcurrency is the number of cancel requests CancelAllJobs has in flight at once
+const cancelConcurrency = 4
+
+// CancelAllJobs cancels every job of the user which has not finished yet
+// The jobs are cancelled concurrently, with at most cancelConcurrency requests in flight, and the errors of the jobs which could not be cancelled are combined into the returned error
+func (c *Client) CancelAllJobs(ctx context.Context) error {
+	filter, err := json.Marshal(map[string]interface{}{
+		"order": "creationDate DESC",
+		"where": map[string]interface{}{"status": map[string][]JobStatus{"inq": activeJobStatuses}},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.conn.getCtx(ctx, "Jobs", "&filter=" + url.QueryEscape(string(filter)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rs []jobResp
+	err = c.conn.decodeChecked(resp.Body, &rs)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(rs))
+	sem := make(chan struct{}, cancelConcurrency)
+	for i, r := range rs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, jobId string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := c.cancelJob(ctx, jobId); err != nil {
+				errs[i] = fmt.Errorf("job %s: %w", jobId, err)
+			}
+		}(i, r.Id)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// DeleteJob deletes a job, removing it from the client cache as well
+// A JobNotFoundErr is returned if the job does not exist.
+func (c *Client) DeleteJob(jobId string) error {
+	resp, err := c.conn.del(fmt.Sprintf("Jobs/%s", jobId), "")
+	if err != nil {
+		if httpErr, ok := err.(*httpErr); ok && httpErr.notFound() {
+			return JobNotFoundErr{JobId: jobId}
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	// A successful response may echo the deleted job, so only an error object wrapped in "error" is an error
+	var r struct {
+		Err *httpErr	`json:"error,omitempty"`
+	}
+	if err = c.conn.decode(resp.Body, &r); err != nil && err != io.EOF {
+		return err
+	}
+	if r.Err != nil {
+		if r.Err.notFound() {
+			return JobNotFoundErr{JobId: jobId}
+		}
+		return r.Err
+	}
+
+	c.mu.Lock()
+	delete(c.jobs, jobId)
+	c.mu.Unlock()
+	return nil
+}
+
+// WaitForJob polls the job on the given interval until it reaches a terminal status, e.g. COMPLETED, and returns it
+// The polling interval configured by WithPollInterval is used if interval isn't positive.
+// If the job has a Timeout, a JobTimeoutErr is returned once it has been waited on for that long.
+// Otherwise, the job is waited on until ctx is done.
+func (c *Client) WaitForJob(ctx context.Context, jobId string, interval time.Duration) (*Job, error) {
+	if interval <= 0 {
+		interval = c.callOptions().pollInterval
+	}
+	return c.waitForJob(ctx, jobId, func(int) time.Duration { return interval })
+}
+
+// WaitForJobBackoff is WaitForJob, but the interval between polls starts at initial and doubles with every poll, up to max
+// The interval starts over from initial whenever the queue position of the job changes, since the job is moving.
+// The polling interval configured by WithPollInterval is used if initial isn't positive,
+// and DefaultMaxPollInterval is used if max isn't positive.
+func (c *Client) WaitForJobBackoff(ctx context.Context, jobId string, initial, max time.Duration) (*Job, error) {
+	if initial <= 0 {
+		initial = c.callOptions().pollInterval
+	}
+	if max <= 0 {
+		max = DefaultMaxPollInterval
+	}
+	if max < initial {
+		max = initial
+	}
+
+	b := &pollBackoff{initial: initial, max: max}
+	return c.waitForJob(ctx, jobId, b.next)
+}
+
+// pollBackoff grows the interval between polls geometrically, from its initial interval up to its max
+type pollBackoff struct {
+	initial, max time.Duration
+	interval time.Duration
+	position int
+}
+
+// next returns the interval before the next poll, given the queue position the job was last polled at
+func (b *pollBackoff) next(position int) time.Duration {
+	if b.interval == 0 || position != b.position {
+		b.interval, b.position = b.initial, position
+		return b.interval
+	}
+
+	b.interval *= 2
+	if b.interval > b.max {
+		b.interval = b.max
+	}
+	return b.interval
+}
+
+// waitForJob polls the job until it reaches a terminal status, waiting for the interval returned by next between polls
+func (c *Client) waitForJob(ctx context.Context, jobId string, next func(position int) time.Duration) (*Job, error) {
+	var timeout <-chan time.Time
+	for {
+		r, err := c.fetchJob(ctx, jobId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		j := c.cacheJob(r)
+
+		j.mu.Lock()
+		status, jobTimeout := j.Status, j.Timeout
+		j.mu.Unlock()
+		if status.IsTerminal() {
+			return j, nil
+		}
+
+		if timeout == nil && jobTimeout > 0 {
+			timer := time.NewTimer(jobTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		var position int
+		if r.InfoQueue != nil {
+			position = r.InfoQueue.Position
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return nil, JobTimeoutErr{JobId: jobId, Timeout: jobTimeout}
+		case <-c.after(next(position)):
+		}
+	}
+}
+
+// StreamJobResults polls a job and emits the result of each circuit as soon as it completes
+// Circuits deduplicated by WithDedupeCircuits are emitted once for every identical circuit of the job.
+// Both channels are closed once the job reaches a terminal state, the context is done, or an error occurs.
+// If the job ends in an error state, the error is sent after any completed results.
+func (c *Client) StreamJobResults(ctx context.Context, jobId string) (<-chan ExpResult, <-chan error) {
+	results := make(chan ExpResult)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(results)
+		defer close(errs)
+
+		interval := c.callOptions().pollInterval
+		emitted := make(map[int]bool)
+
+		c.mu.Lock()
+		j := c.jobs[jobId]
+		c.mu.Unlock()
+
+		for {
+			r, err := c.fetchJob(ctx, jobId)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			for i, q := range r.Qasms {
+				if emitted[i] || executionStatus(q.Status) != JobStatusCompleted {
+					continue
+				}
+
+				result, err := c.transformResult(q.Result.expResult(q.Status, q.ExecutionId))
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				copies := 1
+				if j != nil {
+					copies = j.circuitCopies(i)
+				}
+				for ; copies > 0; copies-- {
+					select {
+					case results <- result:
+					case <-ctx.Done():
+						errs <- ctx.Err()
+						return
+					}
+				}
+				emitted[i] = true
+			}
+
+			status := ParseJobStatus(r.Status)
+			if status.IsTerminal() {
+				if status != JobStatusCompleted {
+					errs <- ApiErr{usrMsg: fmt.Sprintf("job %s ended with status %s", jobId, status)}
+				}
+				return
+			}
+
+			select {
+			case <-c.after(interval):
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return results, errs
+}
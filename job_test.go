@@ -2,7 +2,18 @@ package qiskit_api_go
 
 import (
 	"testing"
+	"sort"
+	"bytes"
+	"errors"
 	"context"
+	"net/http"
+	"encoding/json"
+	"reflect"
+	"fmt"
+	"sync"
+	"time"
+	"strings"
+	"strconv"
 )
 
 const testExpStr = `IBMQASM 2.0;
@@ -19,14 +30,1713 @@ u3(-pi,0,-pi/2) q[0];
 measure q -> c;`
 
 func TestClient_RunExperiment(t *testing.T) {
-	err := testClient.RunExperiment(context.Background(), testExpStr)
+	id, err := newReplayClient(t).RunExperiment(context.Background(), testExpStr)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
+	if id == "" {
+		t.Error("expected the execution id to be returned")
+	}
+}
+
+// jobsHandler mocks the Jobs endpoint, accepting every submitted job as the given job id
+func jobsHandler(t *testing.T, jobId string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/Jobs" {
+			t.Errorf("unexpected request to the API: %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"id": "%s", "status": {"id": "RUNNING"}}`, jobId)
+	})
+}
+
+func TestClient_RunJob(t *testing.T) {
+	var submitted JobRequest
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+			t.Error(err)
+		}
+		jobsHandler(t, "job-1").ServeHTTP(w, r)
+	}), WithSeed(42), WithHPC(true, 8))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	job := NewJob([]string{testExpStr, testExpStr}, 1024, 3)
+	if err := client.RunJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	if job.Id != "job-1" {
+		t.Errorf("expected job id job-1 but got %s", job.Id)
+	}
+	if client.jobs["job-1"] != job {
+		t.Error("expected the job to be cached by its id")
+	}
+
+	expected := JobRequest{
+		Qasms: []JobQasm{{Qasm: testExpStr}, {Qasm: testExpStr}},
+		Shots: 1024,
+		Backend: &JobBackend{Name: DefaultBackend},
+		MaxCredits: 3,
+		Seed: 42,
+		Hpc: &JobHPC{MSO: true, OMP: 8},
+	}
+	submitted.Name = ""
+	if !reflect.DeepEqual(submitted, expected) {
+		t.Errorf("expected job request %+v but got %+v", expected, submitted)
+	}
+
+	t.Run("api_error", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"error": {"status": 400, "code": "QASM_NOT_VALID", "message": "bad qasm"}}`))
+		}))
+		client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+		job := NewJob([]string{testExpStr}, 1, 3)
+		err := client.RunJob(context.Background(), job)
+		if _, ok := err.(*httpErr); !ok {
+			t2.Errorf("expected the API error to be returned but got: %v", err)
+		}
+		if job.Id != "" || len(client.jobs) != 0 {
+			t2.Error("expected a rejected job to be left unsubmitted")
+		}
+	})
+	t.Run("default_shots", func(t2 *testing.T) {
+		for _, shots := range []int{0, 256} {
+			var submitted JobRequest
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+					t2.Error(err)
+				}
+				jobsHandler(t2, "job-1").ServeHTTP(w, r)
+			}), WithShots(shots))
+			client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+			expected := shots
+			if expected == 0 {
+				expected = DefaultShots
+			}
+
+			job := NewJob([]string{testExpStr}, 0, 3)
+			if err := client.RunJob(context.Background(), job); err != nil {
+				t2.Fatal(err)
+			}
+			if submitted.Shots != expected || job.Shots != expected {
+				t2.Errorf("expected a job without shots to run for %d shots but submitted %d", expected, submitted.Shots)
+			}
+		}
+	})
+}
+
+func TestClient_RunJobs(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight, submitted int
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		submitted++
+		id := submitted
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if id == 5 {
+			w.Write([]byte(`{"error": {"status": 400, "code": "QASM_NOT_VALID", "message": "bad qasm"}}`))
+			return
+		}
+		fmt.Fprintf(w, `{"id": "job-%d", "status": {"id": "RUNNING"}}`, id)
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	jobs := make([]*Job, 10)
+	for i := range jobs {
+		jobs[i] = NewJob([]string{testExpStr}, 1, 3)
+	}
+
+	err := client.RunJobs(context.Background(), jobs, 3)
+	if err == nil {
+		t.Error("expected the rejected job to be reported")
+	}
+	if submitted != 10 {
+		t.Errorf("expected 10 submissions but got %d", submitted)
+	}
+	if maxInFlight > 3 {
+		t.Errorf("expected at most 3 submissions in flight but got %d", maxInFlight)
+	}
+
+	client.mu.Lock()
+	cached := len(client.jobs)
+	client.mu.Unlock()
+	if cached != 9 {
+		t.Errorf("expected the 9 submitted jobs to be cached but got %d", cached)
+	}
+
+	t.Run("cancelled", func(t2 *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.RunJobs(ctx, []*Job{NewJob([]string{testExpStr}, 1, 3)}, 1)
+		if !errors.Is(err, context.Canceled) {
+			t2.Errorf("expected the context error but got: %v", err)
+		}
+	})
+}
+
+func TestClient_RunJobs_NamePrefix(t *testing.T) {
+	var mu sync.Mutex
+	var names []string
+	client := newMockClient(t, jobsHandler(t, "job-1"), WithNamePrefix("batch"), WithSubmitHook(func(req *JobRequest) error {
+		mu.Lock()
+		names = append(names, req.Name)
+		mu.Unlock()
+		return nil
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	jobs := []*Job{NewJob([]string{testExpStr}, 1, 3), NewJob([]string{testExpStr}, 1, 3), NewJob([]string{testExpStr}, 1, 3)}
+	if err := client.RunJobs(context.Background(), jobs, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(names)
+	if expected := []string{"batch-1", "batch-2", "batch-3"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected names %v but got %v", expected, names)
+	}
+
+	// Later jobs continue the numbering, unless they are named explicitly
+	names = nil
+	if err := client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RunJobs(context.Background(), jobs[:1], 1, WithName("explicit")); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"batch-4", "explicit"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected names %v but got %v", expected, names)
+	}
+}
+
+func TestClient_RunJob_HPC(t *testing.T) {
+	testCases := []struct {
+		name string
+		omp int
+		expected int
+	}{
+		{name: "default", omp: 0, expected: DefaultOMP},
+		{name: "in_range", omp: 4, expected: 4},
+		{name: "too_many", omp: 99, expected: MaxOMP},
+		{name: "negative", omp: -1, expected: 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var submitted JobRequest
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+					t2.Error(err)
+				}
+				jobsHandler(t2, "job-1").ServeHTTP(w, r)
+			}), WithHPC(true, testCase.omp))
+			client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+			if err := client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1, 3)); err != nil {
+				t2.Fatal(err)
+			}
+			if submitted.Hpc == nil || submitted.Hpc.OMP != testCase.expected {
+				t2.Errorf("expected omp %d but got %+v", testCase.expected, submitted.Hpc)
+			}
+		})
+	}
+
+	t.Run("strict", func(t2 *testing.T) {
+		client := newMockClient(t2, jobsHandler(t2, "job-1"), WithHPC(true, 99), WithStrictLimits())
+		client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+		if err := client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1, 3)); err == nil {
+			t2.Error("expected an out of range omp to be rejected under strict limits")
+		}
+	})
+}
+
+func TestClient_RunExperiment_HPC(t *testing.T) {
+	testCases := []struct {
+		name string
+		omp int
+		expected int
+	}{
+		{name: "default", omp: 0, expected: DefaultOMP},
+		{name: "in_range", omp: 4, expected: 4},
+		{name: "too_many", omp: 99, expected: MaxOMP},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var submitted JobRequest
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+					t2.Error(err)
+				}
+				w.Write([]byte(`{"id": "exec-1"}`))
+			}), WithHPC(true, testCase.omp))
+			client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+			if _, err := client.RunExperiment(context.Background(), testExpStr); err != nil {
+				t2.Fatal(err)
+			}
+			if submitted.Hpc == nil || submitted.Hpc.OMP != testCase.expected {
+				t2.Errorf("expected omp %d but got %+v", testCase.expected, submitted.Hpc)
+			}
+		})
+	}
+
+	t.Run("strict", func(t2 *testing.T) {
+		for _, omp := range []int{-1, 99} {
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t2.Errorf("expected omp %d to be rejected before the request is sent", omp)
+			}), WithHPC(true, omp), WithStrictLimits())
+			client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+			if _, err := client.RunExperiment(context.Background(), testExpStr); err == nil {
+				t2.Errorf("expected an out of range omp %d to be rejected under strict limits", omp)
+			}
+		}
+	})
+}
+
+func TestClient_RunJob_With_Seed(t *testing.T) {
+	var submitted JobRequest
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+			t.Error(err)
+		}
+		jobsHandler(t, "job-1").ServeHTTP(w, r)
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	if err := client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1024, 3), WithSeed(7)); err != nil {
+		t.Fatal(err)
+	}
+
+	if submitted.Seed != 7 {
+		t.Errorf("expected seed 7 to be submitted but got %d", submitted.Seed)
+	}
+}
+
+func TestClient_RunJob_Fail_Backend(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to the API: %s", r.URL.Path)
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	job := NewJob([]string{testExpStr}, 1024, 3)
+	err := client.RunJob(context.Background(), job, WithBackend("ibmqx42"))
+
+	var badBackendErr BadBackendErr
+	if !errors.As(err, &badBackendErr) || badBackendErr.backend != "ibmqx42" {
+		t.Errorf("expected a BadBackendErr but got: %v", err)
+	}
+	if job.Id != "" {
+		t.Error("expected the job to be left unsubmitted")
+	}
+}
+
+// testJobPayload is a canned job as returned by the Jobs endpoint
+const testJobPayload = `{
+	"id": "job-1",
+	"name": "bell",
+	"status": "COMPLETED",
+	"shots": 1024,
+	"maxCredits": 3,
+	"creationDate": "2017-11-28T08:22:20.524Z",
+	"qasms": [{
+		"qasm": "x q[0];",
+		"status": "DONE",
+		"executionId": "exec-1",
+		"result": {"data": {"p": {"qubits": [0], "labels": ["1"], "values": [1]}}}
+	}]
+}`
+
+func TestClient_GetJob(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Jobs/job-1":
+			w.Write([]byte(testJobPayload))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"statusCode": 404, "name": "Error", "message": "Unknown \"Job\" id", "code": "MODEL_NOT_FOUND"}}`))
+		}
+	}))
+
+	job, err := client.GetJob("job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.Id != "job-1" || job.Name != "bell" || job.Shots != 1024 || job.MaxCredits != 3 || job.CreationDate != "2017-11-28T08:22:20.524Z" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job.Status != JobStatusCompleted {
+		t.Errorf("expected job status %s but got %s", JobStatusCompleted, job.Status)
+	}
+	if !reflect.DeepEqual(job.Qasm, []string{"x q[0];"}) {
+		t.Errorf("unexpected job qasm: %v", job.Qasm)
+	}
+	if len(job.Results) != 1 || job.Results[0].Id != "exec-1" || !reflect.DeepEqual(job.Results[0].Result.Measure.Labels, []string{"1"}) {
+		t.Errorf("unexpected job results: %+v", job.Results)
+	}
+
+	cached, err := client.GetJob("job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != job {
+		t.Error("expected the job to be cached by its id")
+	}
+
+	t.Run("not_found", func(t2 *testing.T) {
+		_, err := client.GetJob("missing")
+		notFound, ok := err.(JobNotFoundErr)
+		if !ok {
+			t2.Fatalf("expected a JobNotFoundErr but got: %v", err)
+		}
+		if notFound.JobId != "missing" {
+			t2.Errorf("expected the missing job id but got %s", notFound.JobId)
+		}
+	})
+}
+
+func TestClient_GetJobs(t *testing.T) {
+	var filters []string
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Jobs/job-1":
+			w.Write([]byte(testJobPayload))
+		case "/Jobs/job-2":
+			w.Write([]byte(`{"id": "job-2", "status": "RUNNING"}`))
+		case "/Jobs":
+			filters = append(filters, r.URL.Query().Get("filter"))
+			fmt.Fprintf(w, `[%s, {"id": "job-2", "status": "RUNNING"}]`, testJobPayload)
+		default:
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+	}))
+
+	t.Run("ids", func(t2 *testing.T) {
+		jobs, err := client.GetJobs("job-1", "job-2")
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if len(jobs) != 2 || jobs[0].Id != "job-1" || jobs[1].Id != "job-2" {
+			t2.Fatalf("unexpected jobs: %v", jobs)
+		}
+		if len(filters) != 0 {
+			t2.Error("expected only the given jobs to be fetched")
+		}
+	})
+
+	t.Run("query", func(t2 *testing.T) {
+		filters = nil
+		jobs, err := client.GetJobsWithQuery(JobQuery{Limit: 2, Skip: 4, Status: JobStatusRunning, Backend: "ibmqx4"})
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if len(jobs) != 2 || jobs[0].Id != "job-1" || jobs[1].Status != JobStatusRunning {
+			t2.Fatalf("unexpected jobs: %v", jobs)
+		}
+		if client.jobs["job-2"] != jobs[1] {
+			t2.Error("expected listed jobs to be cached")
+		}
+
+		expected := `{"order":"creationDate DESC","limit":2,"skip":4,"where":{"backend.name":"ibmqx4","status":"RUNNING"}}`
+		if len(filters) != 1 || filters[0] != expected {
+			t2.Errorf("expected filter %s but got %v", expected, filters)
+		}
+	})
+
+	t.Run("recent", func(t2 *testing.T) {
+		filters = nil
+		if _, err := client.GetJobs(); err != nil {
+			t2.Fatal(err)
+		}
+
+		expected := fmt.Sprintf(`{"order":"creationDate DESC","limit":%d}`, DefaultJobsLimit)
+		if len(filters) != 1 || filters[0] != expected {
+			t2.Errorf("expected filter %s but got %v", expected, filters)
+		}
+	})
+}
+
+func TestClient_CancelJob(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected a POST request but got %s", r.Method)
+		}
+
+		switch r.URL.Path {
+		case "/Jobs/job-1/cancel":
+			w.Write([]byte(`{"id": "job-1", "status": "CANCELLED"}`))
+		case "/Jobs/job-2/cancel":
+			w.Write([]byte(`{"id": "job-2", "status": "COMPLETED"}`))
+		case "/Jobs/job-3/cancel":
+			w.Write([]byte(`{"error": {"status": 400, "code": "JOB_NOT_RUNNING", "message": "job can not be cancelled"}}`))
+		default:
+			t.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}
+	}))
+
+	t.Run("cancelled", func(t2 *testing.T) {
+		job := &Job{Id: "job-1", Status: JobStatusRunning}
+		client.jobs[job.Id] = job
+
+		if err := client.CancelJob("job-1"); err != nil {
+			t2.Fatal(err)
+		}
+		if job.Status != JobStatusCancelled {
+			t2.Errorf("expected the cached job to be %s but got %s", JobStatusCancelled, job.Status)
+		}
+		if _, cached := client.jobs["job-1"]; cached {
+			t2.Error("expected the cancelled job to be removed from the cache")
+		}
+	})
+
+	t.Run("already_finished", func(t2 *testing.T) {
+		err := client.CancelJob("job-2")
+		notCancelled, ok := err.(JobNotCancelledErr)
+		if !ok {
+			t2.Fatalf("expected a JobNotCancelledErr but got: %v", err)
+		}
+		if notCancelled.Status != JobStatusCompleted {
+			t2.Errorf("expected the job to be reported as %s but got %s", JobStatusCompleted, notCancelled.Status)
+		}
+	})
+
+	t.Run("refused", func(t2 *testing.T) {
+		if _, ok := client.CancelJob("job-3").(JobNotCancelledErr); !ok {
+			t2.Error("expected a JobNotCancelledErr")
+		}
+	})
+}
+
+func TestClient_CancelAllJobs(t *testing.T) {
+	var mu sync.Mutex
+	var filter string
+	var cancelled []string
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/Jobs":
+			filter = r.URL.Query().Get("filter")
+			w.Write([]byte(`[{"id": "job-1", "status": "RUNNING"}, {"id": "job-2", "status": "QUEUED"}, {"id": "job-3", "status": "RUNNING"}]`))
+		case r.URL.Path == "/Jobs/job-3/cancel":
+			w.Write([]byte(`{"id": "job-3", "status": "COMPLETED"}`))
+		case strings.HasSuffix(r.URL.Path, "/cancel"):
+			jobId := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/Jobs/"), "/cancel")
+			cancelled = append(cancelled, jobId)
+			w.Write([]byte(`{"id": "` + jobId + `", "status": "CANCELLED"}`))
+		default:
+			t.Errorf("unexpected request to the API: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	client.jobs["job-1"] = &Job{Id: "job-1", Status: JobStatusRunning}
+
+	err := client.CancelAllJobs(context.Background())
+	var notCancelled JobNotCancelledErr
+	if !errors.As(err, &notCancelled) || notCancelled.JobId != "job-3" {
+		t.Errorf("expected job-3 to fail to be cancelled but got: %v", err)
+	}
+
+	sort.Strings(cancelled)
+	if !reflect.DeepEqual(cancelled, []string{"job-1", "job-2"}) {
+		t.Errorf("expected the active jobs to be cancelled but got: %v", cancelled)
+	}
+	if expected := `{"order":"creationDate DESC","where":{"status":{"inq":["CREATING","VALIDATING","QUEUED","RUNNING"]}}}`; filter != expected {
+		t.Errorf("expected filter %s but got %s", expected, filter)
+	}
+	if _, cached := client.jobs["job-1"]; cached {
+		t.Error("expected the cancelled job to be removed from the cache")
+	}
+}
+
+func TestClient_CancelAllJobs_Concurrency(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight, cancelled int
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			var jobs []string
+			for i := 0; i < 3*cancelConcurrency; i++ {
+				jobs = append(jobs, fmt.Sprintf(`{"id": "job-%d", "status": "RUNNING"}`, i))
+			}
+			w.Write([]byte("[" + strings.Join(jobs, ",") + "]"))
+			return
+		}
+
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		cancelled++
+		mu.Unlock()
+		w.Write([]byte(`{"status": "CANCELLED"}`))
+	}))
+
+	if err := client.CancelAllJobs(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if cancelled != 3*cancelConcurrency {
+		t.Errorf("expected %d jobs to be cancelled but got %d", 3*cancelConcurrency, cancelled)
+	}
+	if maxInFlight > cancelConcurrency {
+		t.Errorf("expected at most %d cancels in flight but got %d", cancelConcurrency, maxInFlight)
+	}
+}
+
+func TestClient_DeleteJob(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("expected a DELETE request but got %s", r.Method)
+		}
+
+		switch r.URL.Path {
+		case "/Jobs/job-1":
+			w.Write([]byte(`{"count": 1}`))
+		case "/Jobs/job-3":
+			w.Write([]byte(`{"id": "job-3", "name": "Experiment #1", "status": "CANCELLED"}`))
+		case "/Jobs/job-4":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"statusCode": 404, "name": "Error", "message": "Unknown \"Job\" id", "code": "MODEL_NOT_FOUND"}}`))
+		}
+	}))
+	client.jobs["job-1"] = &Job{Id: "job-1", Status: JobStatusCompleted}
+
+	if err := client.DeleteJob("job-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, cached := client.jobs["job-1"]; cached {
+		t.Error("expected the deleted job to be removed from the cache")
+	}
+
+	if _, ok := client.DeleteJob("job-2").(JobNotFoundErr); !ok {
+		t.Error("expected a JobNotFoundErr for an unknown job")
+	}
+
+	t.Run("echoed_job", func(t2 *testing.T) {
+		if err := client.DeleteJob("job-3"); err != nil {
+			t2.Errorf("expected a response echoing the deleted job to succeed but got: %v", err)
+		}
+	})
+
+	t.Run("no_content", func(t2 *testing.T) {
+		if err := client.DeleteJob("job-4"); err != nil {
+			t2.Errorf("expected an empty response to succeed but got: %v", err)
+		}
+	})
+}
+
+func TestClient_WaitForJob(t *testing.T) {
+	var polls int
+	var mu sync.Mutex
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		polls++
+		switch {
+		case r.URL.Path == "/Jobs/job-1" && polls >= 3:
+			w.Write([]byte(testJobPayload))
+		default:
+			w.Write([]byte(`{"id": "` + strings.TrimPrefix(r.URL.Path, "/Jobs/") + `", "status": "RUNNING"}`))
+		}
+	}))
+
+	t.Run("completed", func(t2 *testing.T) {
+		job, err := client.WaitForJob(context.Background(), "job-1", time.Millisecond)
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if job.Status != JobStatusCompleted || polls != 3 {
+			t2.Errorf("expected job to complete after 3 polls but got status %s after %d polls", job.Status, polls)
+		}
+	})
+
+	t.Run("context", func(t2 *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.WaitForJob(ctx, "job-2", time.Hour)
+		if err != context.DeadlineExceeded {
+			t2.Errorf("expected the context error but got: %v", err)
+		}
+		if time.Since(start) > time.Second {
+			t2.Error("expected waiting to stop as soon as the context is done")
+		}
+	})
+
+	t.Run("timeout", func(t2 *testing.T) {
+		client.jobs["job-3"] = &Job{Id: "job-3", Timeout: 10 * time.Millisecond}
+
+		_, err := client.WaitForJob(context.Background(), "job-3", time.Hour)
+		timeoutErr, ok := err.(JobTimeoutErr)
+		if !ok {
+			t2.Fatalf("expected a JobTimeoutErr but got: %v", err)
+		}
+		if timeoutErr.JobId != "job-3" || timeoutErr.Timeout != 10 * time.Millisecond {
+			t2.Errorf("unexpected timeout error: %v", timeoutErr)
+		}
+	})
+}
+
+func TestClient_RunExperimentAndWait(t *testing.T) {
+	var polls, submitted int
+	var mu sync.Mutex
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/codes/execute":
+			submitted++
+			w.Write([]byte(fmt.Sprintf(`{"id": "exec-%d", "status": {"id": "RUNNING"}}`, submitted)))
+		case r.URL.Path == "/Executions/exec-1" && polls < 2:
+			polls++
+			w.Write([]byte(`{"id": "exec-1", "status": {"id": "RUNNING"}}`))
+		case r.URL.Path == "/Executions/exec-1":
+			polls++
+			w.Write([]byte(`{"id": "exec-1", "status": {"id": "COMPLETED"}, "result": {"data": {"p": {"labels": ["00", "11"], "values": [0.5, 0.5]}}}}`))
+		default:
+			w.Write([]byte(`{"id": "` + strings.TrimPrefix(r.URL.Path, "/Executions/") + `", "status": {"id": "RUNNING"}}`))
+		}
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	t.Run("completed", func(t2 *testing.T) {
+		res, err := client.RunExperimentAndWait(context.Background(), testExpStr, time.Millisecond)
+		if err != nil {
+			t2.Fatal(err)
+		}
+		if polls != 3 || res.Id != "exec-1" || res.Status != "COMPLETED" || !reflect.DeepEqual(res.Result.Measure.Labels, []string{"00", "11"}) {
+			t2.Errorf("unexpected result after %d polls: %+v", polls, res)
+		}
+	})
+
+	t.Run("context", func(t2 *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+		defer cancel()
+
+		_, err := client.RunExperimentAndWait(ctx, testExpStr, time.Hour)
+		if err != context.DeadlineExceeded {
+			t2.Errorf("expected the context error but got: %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t2 *testing.T) {
+		_, err := client.RunExperimentAndWait(context.Background(), testExpStr, time.Hour, JobTimeout(10 * time.Millisecond))
+		timeoutErr, ok := err.(ExecutionTimeoutErr)
+		if !ok {
+			t2.Fatalf("expected an ExecutionTimeoutErr but got: %v", err)
+		}
+		if !strings.HasPrefix(timeoutErr.ExecutionId, "exec-") || !strings.Contains(err.Error(), "execution \"" + timeoutErr.ExecutionId + "\"") {
+			t2.Errorf("unexpected timeout error: %v", err)
+		}
+	})
+}
+
+func TestClient_WaitForJobBackoff(t *testing.T) {
+	// The job waits at position 3, then moves to position 2, then completes
+	positions := []int{3, 3, 3, 3, 2, 2}
+	var polls int
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if polls == len(positions) {
+			w.Write([]byte(testJobPayload))
+			return
+		}
+		fmt.Fprintf(w, `{"id": "job-1", "status": "QUEUED", "infoQueue": {"status": "PENDING_IN_QUEUE", "position": %d}}`, positions[polls])
+		polls++
+	}))
+
+	// Fake the clock, recording every interval waited for instead of waiting
+	var intervals []time.Duration
+	client.after = func(d time.Duration) <-chan time.Time {
+		intervals = append(intervals, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+
+	job, err := client.WaitForJobBackoff(context.Background(), "job-1", time.Second, 4 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != JobStatusCompleted {
+		t.Errorf("expected the job to complete but got %s", job.Status)
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second, time.Second, 2 * time.Second}
+	if !reflect.DeepEqual(intervals, expected) {
+		t.Errorf("expected intervals %v but got %v", expected, intervals)
+	}
+}
+
+func TestClient_RunJob_StrictLimits(t *testing.T) {
+	newClient := func(options ...ClientOption) *Client {
+		client := newMockClient(t, jobsHandler(t, "job-1"), options...)
+		client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+		return client
+	}
+
+	t.Run("clamp", func(t2 *testing.T) {
+		job := NewJob([]string{testExpStr}, MaxShots+1, 3)
+		if err := newClient().RunJob(context.Background(), job); err != nil {
+			t2.Fatal(err)
+		}
+		if job.Shots != MaxShots {
+			t2.Errorf("expected shots to be clamped to %d but got %d", MaxShots, job.Shots)
+		}
+	})
+
+	t.Run("strict", func(t2 *testing.T) {
+		job := NewJob([]string{testExpStr}, MaxShots+1, 3)
+		if err := newClient(WithStrictLimits()).RunJob(context.Background(), job); err == nil {
+			t2.Error("expected an error for exceeding the maximum shots")
+		}
+	})
+
+	t.Run("strict_within_limits", func(t2 *testing.T) {
+		job := NewJob([]string{testExpStr}, MaxShots, 3)
+		if err := newClient(WithStrictLimits()).RunJob(context.Background(), job); err != nil {
+			t2.Fatal(err)
+		}
+	})
+}
+
+func TestClient_JobQueuePosition(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+		position int
+	}{
+		{name: "queued", body: `{"id": "job-1", "status": "RUNNING", "infoQueue": {"status": "PENDING_IN_QUEUE", "position": 4}}`, position: 4},
+		{name: "running", body: `{"id": "job-1", "status": "RUNNING"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/Jobs/job-1" {
+					t2.Errorf("unexpected request to the API: %s", r.URL.Path)
+				}
+				w.Write([]byte(testCase.body))
+			}))
+
+			position, err := client.JobQueuePosition("job-1")
+			if err != nil {
+				t2.Fatal(err)
+			}
+			if position != testCase.position {
+				t2.Errorf("expected queue position %d but got %d", testCase.position, position)
+			}
+		})
+	}
+}
+
+func TestQueueInfo(t *testing.T) {
+	body := `{"id": "exec-1", "status": {"id": "RUNNING"}, "infoQueue": {"status": "PENDING_IN_QUEUE", "position": 2, "estimatedStartTime": "2018-03-02T10:00:00.000Z", "estimatedCompleteTime": "2018-03-02T10:05:00.000Z"}}`
+
+	var r jobExecResp
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &QueueInfo{Status: "PENDING_IN_QUEUE", Position: 2, EstimatedStartTime: "2018-03-02T10:00:00.000Z", EstimatedCompleteTime: "2018-03-02T10:05:00.000Z"}
+	if info := r.expResult().InfoQueue; !reflect.DeepEqual(info, expected) {
+		t.Errorf("expected queue info %+v but got %+v", expected, info)
+	}
+}
+
+func TestQueueInfo_UnmarshalJSON(t *testing.T) {
+	testCases := map[string]struct {
+		body string
+		expected QueueInfo
+	}{
+		"position": {
+			body: `{"status": "PENDING_IN_QUEUE", "position": 4}`,
+			expected: QueueInfo{Status: "PENDING_IN_QUEUE", Position: 4},
+		},
+		"string_position": {
+			body: `{"status": "PENDING_IN_QUEUE", "position": "12"}`,
+			expected: QueueInfo{Status: "PENDING_IN_QUEUE", Position: 12},
+		},
+		"estimates": {
+			body: `{"status": "PENDING_IN_QUEUE", "position": 1, "estimatedStartTime": "2019-06-14T09:47:28.000Z", "estimatedCompleteTime": "2019-06-14T09:49:28.000Z", "hubPriority": 0.5}`,
+			expected: QueueInfo{Status: "PENDING_IN_QUEUE", Position: 1, EstimatedStartTime: "2019-06-14T09:47:28.000Z", EstimatedCompleteTime: "2019-06-14T09:49:28.000Z"},
+		},
+		"epoch_estimates": {
+			body: `{"status": "PENDING_IN_QUEUE", "position": 2, "estimatedStartTime": 1560505648000, "estimatedCompleteTime": null}`,
+			expected: QueueInfo{Status: "PENDING_IN_QUEUE", Position: 2, EstimatedStartTime: "2019-06-14T09:47:28.000Z"},
+		},
+		"running": {
+			body: `{"status": "RUNNING"}`,
+			expected: QueueInfo{Status: "RUNNING"},
+		},
+		"legacy_string": {
+			body: `"PENDING_IN_QUEUE"`,
+			expected: QueueInfo{Status: "PENDING_IN_QUEUE"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			var info QueueInfo
+			if err := json.Unmarshal([]byte(testCase.body), &info); err != nil {
+				t2.Fatal(err)
+			}
+			if info != testCase.expected {
+				t2.Errorf("expected queue info %+v but got %+v", testCase.expected, info)
+			}
+		})
+	}
+
+	t.Run("invalid_position", func(t2 *testing.T) {
+		var info QueueInfo
+		if err := json.Unmarshal([]byte(`{"position": "first"}`), &info); err == nil {
+			t2.Error("expected an invalid position to be rejected")
+		}
+	})
+}
+
+func TestJob_QueuePosition(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Jobs/job-1":
+			w.Write([]byte(`{"id": "job-1", "status": "QUEUED", "infoQueue": {"status": "PENDING_IN_QUEUE", "position": "3"}}`))
+		default:
+			w.Write([]byte(testJobPayload))
+		}
+	}))
+
+	job, err := client.GetJob("job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position, queued := job.QueuePosition(); !queued || position != 3 {
+		t.Errorf("expected the job to be queued at position 3 but got %d, %v", position, queued)
+	}
+
+	job, err = client.GetJob("job-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, queued := job.QueuePosition(); queued {
+		t.Error("expected a completed job not to be queued")
+	}
+}
+
+func TestJob_JSON(t *testing.T) {
+	job := NewJob([]string{testExpStr}, 1024, 3)
+	job.Id = "job-1"
+	job.Name = "bell"
+	job.Timeout = time.Minute
+	job.CodeId = "code-1"
+	job.CreationDate = "2018-03-02T10:00:00.000Z"
+	job.Status = JobStatusCompleted
+	job.Results = []ExpResult{{Id: "exec-1", Status: "DONE"}}
+
+	b, err := json.Marshal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"mu", "isExperiment", "requestedShots"} {
+		if _, ok := raw[field]; ok {
+			t.Errorf("expected %s not to be marshaled", field)
+		}
+	}
+
+	var decoded Job
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := jobData{Id: job.Id, Name: job.Name, Timeout: job.Timeout, Shots: job.Shots, MaxCredits: job.MaxCredits, Qasm: job.Qasm, CodeId: job.CodeId, CreationDate: job.CreationDate, Results: job.Results, Status: job.Status}
+	actual := jobData{Id: decoded.Id, Name: decoded.Name, Timeout: decoded.Timeout, Shots: decoded.Shots, MaxCredits: decoded.MaxCredits, Qasm: decoded.Qasm, CodeId: decoded.CodeId, CreationDate: decoded.CreationDate, Results: decoded.Results, Status: decoded.Status}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected job %+v after a round trip but got %+v", expected, actual)
+	}
+}
+
+func TestParseJobStatus(t *testing.T) {
+	testCases := []struct {
+		status string
+		expected JobStatus
+		terminal bool
+		isError bool
+	}{
+		{status: "CREATING", expected: JobStatusCreating},
+		{status: "VALIDATING", expected: JobStatusValidating},
+		{status: "QUEUED", expected: JobStatusQueued},
+		{status: "running", expected: JobStatusRunning},
+		{status: "COMPLETED", expected: JobStatusCompleted, terminal: true},
+		{status: "CANCELLED", expected: JobStatusCancelled, terminal: true},
+		{status: "ERROR_CREATING_JOB", expected: JobStatusErrorCreating, terminal: true, isError: true},
+		{status: "ERROR_VALIDATING_JOB", expected: JobStatusErrorValidating, terminal: true, isError: true},
+		{status: "ERROR_RUNNING_JOB", expected: JobStatusErrorRunning, terminal: true, isError: true},
+		{status: "ERROR", expected: JobStatusError, terminal: true, isError: true},
+		{status: "PAUSED", expected: JobStatusUnknown},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.status, func(t2 *testing.T) {
+			status := ParseJobStatus(testCase.status)
+			if status != testCase.expected {
+				t2.Errorf("expected status %s but got %s", testCase.expected, status)
+			}
+			if status.IsTerminal() != testCase.terminal {
+				t2.Errorf("expected IsTerminal to be %v", testCase.terminal)
+			}
+			if status.IsError() != testCase.isError {
+				t2.Errorf("expected IsError to be %v", testCase.isError)
+			}
+		})
+	}
+}
+
+func TestClient_GetJobStatus(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Jobs/job-1/status" {
+			t.Errorf("expected the status only endpoint but got: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"status": "RUNNING"}`))
+	}))
+
+	status, err := client.GetJobStatus(context.Background(), "job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status != JobStatusRunning {
+		t.Errorf("expected status %s but got %s", JobStatusRunning, status)
+	}
+}
+
+func TestClient_GetJobQASMs(t *testing.T) {
+	qasms := []string{testExpStr, "qreg q[1];\ncreg c[1];\nmeasure q -> c;"}
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Jobs/job-1" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": "job-1",
+			"status": "COMPLETED",
+			"qasms": []map[string]string{{"qasm": qasms[0]}, {"qasm": qasms[1]}},
+		})
+	}))
+
+	job := NewJob(qasms, 1, 3)
+	job.setId("job-1")
+	client.jobs[job.Id] = job
+
+	if _, err := job.CircuitQASMs(); err == nil {
+		t.Error("expected an error before the job has been fetched")
+	}
+
+	stored, err := client.GetJobQASMs(context.Background(), "job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored, qasms) {
+		t.Errorf("expected qasms to round trip but got: %v", stored)
+	}
+
+	cached, err := job.CircuitQASMs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cached, qasms) {
+		t.Errorf("expected cached job qasms to round trip but got: %v", cached)
+	}
+}
+
+func TestClient_SubmitHook(t *testing.T) {
+	errForbidden := fmt.Errorf("backend is forbidden")
+	forbidReal := WithSubmitHook(func(req *JobRequest) error {
+		if req.Backend.Name == "real" || req.Backend.Name == "ibmqx4" {
+			return errForbidden
+		}
+		return nil
+	})
+
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to the API: %s", r.URL.Path)
+	}), forbidReal)
+	client.SetBackendCache(Backends{"ibmqx4": &Backend{Name: "ibmqx4"}})
+
+	if _, err := client.RunExperiment(context.Background(), testExpStr, WithBackend("ibmqx2")); err != errForbidden {
+		t.Errorf("expected experiment to be blocked but got: %v", err)
+	}
+
+	job := NewJob([]string{testExpStr}, 1, 3)
+	if err := client.RunJob(context.Background(), job, WithBackend("ibmqx4")); err != errForbidden {
+		t.Errorf("expected job to be blocked but got: %v", err)
+	}
+}
+
+func TestExpResult_BlochVectors(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+		expected []BlochVector
+	}{
+		{name: "null", body: `{"result": {"bloch": null}}`},
+		{name: "missing", body: `{"result": {}}`},
+		{name: "empty", body: `{"result": {"bloch": []}}`, expected: []BlochVector{}},
+		{name: "triples", body: `{"result": {"bloch": [[0, 0, 1], [1, 0, 0]]}}`, expected: []BlochVector{{Z: 1}, {X: 1}}},
+		{name: "objects", body: `{"result": {"bloch": [{"x": 0, "y": 1, "z": 0}]}}`, expected: []BlochVector{{Y: 1}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var result ExpResult
+			if err := json.Unmarshal([]byte(testCase.body), &result); err != nil {
+				t2.Fatal(err)
+			}
+
+			if vectors := result.BlochVectors(); len(vectors) != len(testCase.expected) || (len(vectors) > 0 && !reflect.DeepEqual(vectors, testCase.expected)) {
+				t2.Errorf("expected %v but got %v", testCase.expected, vectors)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t2 *testing.T) {
+		var result ExpResult
+		if err := json.Unmarshal([]byte(`{"result": {"bloch": [[0, 1]]}}`), &result); err == nil {
+			t2.Error("expected an error for a bloch vector without 3 components")
+		}
+	})
+}
+
+func TestClient_StreamJobResults(t *testing.T) {
+	// Circuit 1 completes before circuit 0, reported in lowercase, then the job completes
+	polls := []string{
+		`{"id": "job-1", "status": "RUNNING", "qasms": [{"status": "RUNNING"}, {"status": "RUNNING"}]}`,
+		`{"id": "job-1", "status": "RUNNING", "qasms": [{"status": "RUNNING"}, {"status": "done", "executionId": "exec-1"}]}`,
+		`{"id": "job-1", "status": "COMPLETED", "qasms": [{"status": "DONE", "executionId": "exec-0"}, {"status": "DONE", "executionId": "exec-1"}]}`,
+	}
+
+	var mu sync.Mutex
+	poll := 0
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if poll >= len(polls) {
+			t.Errorf("job was polled after it completed")
+			poll = len(polls) - 1
+		}
+		w.Write([]byte(polls[poll]))
+		poll++
+	}))
+
+	// Fake the clock, recording every interval waited for instead of waiting
+	var intervals []time.Duration
+	client.after = func(d time.Duration) <-chan time.Time {
+		mu.Lock()
+		intervals = append(intervals, d)
+		mu.Unlock()
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+
+	results, errs := client.StreamJobResults(context.Background(), "job-1")
+
+	var order []string
+	for result := range results {
+		order = append(order, result.Id)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(order, []string{"exec-1", "exec-0"}) {
+		t.Errorf("expected results in completion order but got: %v", order)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if expected := []time.Duration{DefaultPollInterval, DefaultPollInterval}; !reflect.DeepEqual(intervals, expected) {
+		t.Errorf("expected intervals %v but got %v", expected, intervals)
+	}
+}
+
+func TestClient_RunExperiment_NameLength(t *testing.T) {
+	var submitted JobRequest
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"id": "exec-1"}`))
+	})
+	name := strings.Repeat("a", 300)
+
+	_, err := newMockClient(t, handler).RunExperiment(context.Background(), testExpStr, WithName(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(submitted.Name) != MaxNameLength || !strings.HasSuffix(submitted.Name, "...") {
+		t.Errorf("expected name to be truncated to %d characters but got %d: %s", MaxNameLength, len(submitted.Name), submitted.Name)
+	}
+
+	_, err = newMockClient(t, handler, WithStrictLimits()).RunExperiment(context.Background(), testExpStr, WithName(name))
+	if err == nil {
+		t.Error("expected long name to be rejected under strict limits")
+	}
+}
+
+func TestClient_RunExperiment_MaxShots(t *testing.T) {
+	var shots string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shots = r.URL.Query().Get("shots")
+		w.Write([]byte(`{"id": "exec-1"}`))
+	})
+
+	_, err := newMockClient(t, handler).RunExperiment(context.Background(), testExpStr, WithShots(100000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shots != strconv.Itoa(MaxShots) {
+		t.Errorf("expected shots to be clamped to %d but got %s", MaxShots, shots)
+	}
+
+	_, err = newMockClient(t, handler, WithStrictLimits()).RunExperiment(context.Background(), testExpStr, WithShots(100000))
+	if err == nil {
+		t.Error("expected too many shots to be rejected under strict limits")
+	}
+}
+
+func TestClient_RunExperiment_RegisterSizeErr(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"status": 400, "code": "QASM_NOT_VALID", "message": "Error parsing QASM. Error parsing qasm number. register exceed the number of qubits, it can't be greater than 5"}}`))
+	})
+
+	client := newMockClient(t, handler)
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	testCases := map[string]func() error{
+		"RunExperiment": func() error { _, err := client.RunExperiment(context.Background(), testExpStr); return err },
+		"RunJob": func() error { return client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1, 3)) },
+	}
+
+	for name, run := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			err := run()
+			regErr, ok := err.(RegisterSizeErr)
+			if !ok {
+				t2.Fatalf("expected a RegisterSizeErr but got: %v", err)
+			}
+			if regErr.Max != 5 {
+				t2.Errorf("expected a maximum of 5 qubits but got %d", regErr.Max)
+			}
+		})
+	}
+}
+
+func TestClient_InsufficientCreditsErr(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"status": 400, "code": "NOT_CREDITS_AVALIABLES", "message": "Not enough credits to run the job. It requires 15 credits and you have 5 credits remaining"}}`))
+	})
+
+	client := newMockClient(t, handler)
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	testCases := map[string]func() error{
+		"RunExperiment": func() error { _, err := client.RunExperiment(context.Background(), testExpStr); return err },
+		"RunJob": func() error { return client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1, 3)) },
+	}
+
+	for name, run := range testCases {
+		t.Run(name, func(t2 *testing.T) {
+			err := run()
+			creditsErr, ok := err.(InsufficientCreditsErr)
+			if !ok {
+				t2.Fatalf("expected an InsufficientCreditsErr but got: %v", err)
+			}
+			if creditsErr.Required != 15 || creditsErr.Available != 5 {
+				t2.Errorf("expected 15 credits required and 5 available but got %d and %d", creditsErr.Required, creditsErr.Available)
+			}
+
+			var apiErr *httpErr
+			if !errors.As(err, &apiErr) || apiErr.Code != "NOT_CREDITS_AVALIABLES" {
+				t2.Errorf("expected the error to unwrap to the API error but got: %v", apiErr)
+			}
+		})
+	}
+
+	t.Run("message_only", func(t2 *testing.T) {
+		err := insufficientCreditsErr(&httpErr{Status: 400, Message: "Insufficient credits"})
+		creditsErr, ok := err.(InsufficientCreditsErr)
+		if !ok {
+			t2.Fatalf("expected an InsufficientCreditsErr but got: %v", err)
+		}
+		if creditsErr.Required != 0 || creditsErr.Available != 0 {
+			t2.Errorf("expected unknown credits but got %d and %d", creditsErr.Required, creditsErr.Available)
+		}
+
+		if _, ok := insufficientCreditsErr(&httpErr{Status: 400, Message: "QASM_NOT_VALID"}).(*httpErr); !ok {
+			t2.Error("expected other errors to be returned as is")
+		}
+	})
 }
 
-func TestClient_RunJob(t *testing.T) {}
-func TestClient_RunJob_With_Seed(t *testing.T) {}
-func TestClient_RunJob_Fail_Backend(t *testing.T) {}
+func TestClient_RunExperiment_QASMVersion(t *testing.T) {
+	testCases := []struct {
+		version string
+		qasm string
+		codeType string
+		expectedQasm string
+	}{
+		{version: "", qasm: "OPENQASM 2.0;qreg q[1];x q[0];", codeType: "QASM2", expectedQasm: "qreg q[1];x q[0];"},
+		{version: "2.0", qasm: "OPENQASM 2.0;qreg q[1];x q[0];", codeType: "QASM2", expectedQasm: "qreg q[1];x q[0];"},
+		{version: "3.0", qasm: "OPENQASM 3.0;qubit[1] q;x q[0];", codeType: "QASM3", expectedQasm: "qubit[1] q;x q[0];"},
+	}
 
-func TestClient_GetJobs(t *testing.T) {}
\ No newline at end of file
+	for _, testCase := range testCases {
+		t.Run("version_" + testCase.version, func(t2 *testing.T) {
+			var submitted JobRequest
+			client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+					t2.Error(err)
+				}
+				w.Write([]byte(`{"id": "exec-1"}`))
+			}))
+
+			var options []ClientOption
+			if testCase.version != "" {
+				options = append(options, WithQASMVersion(testCase.version))
+			}
+			if _, err := client.RunExperiment(context.Background(), testCase.qasm, options...); err != nil {
+				t2.Fatal(err)
+			}
+
+			if submitted.CodeType != testCase.codeType || submitted.Qasm != testCase.expectedQasm {
+				t2.Errorf("expected code type %s with qasm %q but got %s with %q", testCase.codeType, testCase.expectedQasm, submitted.CodeType, submitted.Qasm)
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t2.Error("expected no request for an unsupported version")
+		}))
+		if _, err := client.RunExperiment(context.Background(), testExpStr, WithQASMVersion("1.0")); err == nil {
+			t2.Error("expected an unsupported version to be rejected")
+		}
+	})
+}
+
+func TestJob_CreditsConsumed(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+		credits float64
+		ok bool
+	}{
+		{name: "completed", body: `{"id": "job-1", "status": "COMPLETED", "creditsUsed": 3, "qasms": [{"qasm": "x q[0];", "status": "DONE"}]}`, credits: 3, ok: true},
+		{name: "running", body: `{"id": "job-1", "status": "RUNNING", "creditsUsed": 3}`},
+		{name: "simulator", body: `{"id": "job-1", "status": "COMPLETED"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			var r jobResp
+			if err := json.Unmarshal([]byte(testCase.body), &r); err != nil {
+				t2.Fatal(err)
+			}
+
+			job := &Job{}
+			job.update(r)
+
+			credits, ok := job.CreditsConsumed()
+			if credits != testCase.credits || ok != testCase.ok {
+				t2.Errorf("expected (%v, %v) but got (%v, %v)", testCase.credits, testCase.ok, credits, ok)
+			}
+		})
+	}
+}
+
+func TestClient_RunExperiment_Memory(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req JobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if !req.Memory {
+			t.Error("expected the memory flag to be sent")
+		}
+		w.Write([]byte(`{"id": "exec-1"}`))
+	}))
+
+	if _, err := client.RunExperiment(context.Background(), testExpStr, WithMemory(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	var r jobExecResp
+	if err := json.Unmarshal([]byte(`{"id": "exec-1", "result": {"data": {"memory": ["00000", "00001", "00000"]}}}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if memory := r.expResult().Memory; !reflect.DeepEqual(memory, []string{"00000", "00001", "00000"}) {
+		t.Errorf("unexpected memory: %v", memory)
+	}
+}
+
+func TestJob_submitted(t *testing.T) {
+	body := `{"id": "job-1", "status": {"id": "RUNNING"}, "code": {"id": "code-1", "name": "Experiment #1", "codeType": "QASM2", "qasm": "x q[0];"}}`
+
+	var r jobExecResp
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	job := NewJob([]string{testExpStr}, 1, 3)
+	job.submitted(r)
+
+	if job.Id != "job-1" || job.CodeId != "code-1" {
+		t.Errorf("expected job id job-1 and code id code-1 but got %s and %s", job.Id, job.CodeId)
+	}
+}
+
+func TestClient_RunJob_DedupeCircuits(t *testing.T) {
+	bell, err := NewCircuitBuilder().H(0).CX(0, 1).Measure(0, 0).Measure(1, 1).QASM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	flip, err := NewCircuitBuilder().X(0).Measure(0, 0).QASM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var submitted []JobQasm
+	client := newMockClient(t, jobsHandler(t, "job-1"), WithDedupeCircuits(), WithSubmitHook(func(req *JobRequest) error {
+		submitted = req.Qasms
+		return nil
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	job := NewJob([]string{bell, flip, bell, flip, bell}, 1, 3)
+	if err := client.RunJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(submitted) != 2 || submitted[0].Qasm != bell || submitted[1].Qasm != flip {
+		t.Fatalf("expected only the unique circuits to be submitted but got: %v", submitted)
+	}
+
+	results := job.fanOut([]ExpResult{{Id: "bell"}, {Id: "flip"}})
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Id)
+	}
+	if !reflect.DeepEqual(ids, []string{"bell", "flip", "bell", "flip", "bell"}) {
+		t.Errorf("expected results to be fanned out to every original circuit but got: %v", ids)
+	}
+	if job.circuitCopies(0) != 3 || job.circuitCopies(1) != 2 {
+		t.Errorf("unexpected circuit copies: %d %d", job.circuitCopies(0), job.circuitCopies(1))
+	}
+}
+
+// testMultiJobPayload is a canned job with two experiments, as returned by the Jobs endpoint
+const testMultiJobPayload = `{
+	"id": "job-2",
+	"status": "COMPLETED",
+	"qasms": [{
+		"qasm": "x q[0];",
+		"status": "DONE",
+		"executionId": "exec-1",
+		"result": {"data": {"p": {"qubits": [0], "labels": ["1"], "values": [1]}}}
+	}, {
+		"qasm": "h q[0];",
+		"status": "DONE",
+		"executionId": "exec-2",
+		"result": {"data": {"p": {"qubits": [0], "labels": ["0", "1"], "values": [0.5, 0.5]}}}
+	}]
+}`
+
+func TestJob_MultipleExperiments(t *testing.T) {
+	check := func(t2 *testing.T, results []ExpResult) {
+		if len(results) != 2 {
+			t2.Fatalf("expected a result for both experiments but got %d", len(results))
+		}
+		if results[0].Id != "exec-1" || !reflect.DeepEqual(results[0].Result.Measure.Labels, []string{"1"}) {
+			t2.Errorf("unexpected result of the first experiment: %+v", results[0])
+		}
+		if results[1].Id != "exec-2" || !reflect.DeepEqual(results[1].Result.Measure.Values, []float64{0.5, 0.5}) {
+			t2.Errorf("unexpected result of the second experiment: %+v", results[1])
+		}
+	}
+
+	t.Run("submitted", func(t2 *testing.T) {
+		var r jobExecResp
+		if err := json.Unmarshal([]byte(strings.Replace(testMultiJobPayload, `"COMPLETED"`, `{"id": "COMPLETED"}`, 1)), &r); err != nil {
+			t2.Fatal(err)
+		}
+
+		job := NewJob([]string{"x q[0];", "h q[0];"}, 1, 3)
+		job.submitted(r)
+		check(t2, job.Results)
+	})
+
+	t.Run("GetJob", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(testMultiJobPayload))
+		}))
+
+		job, err := client.GetJob("job-2")
+		if err != nil {
+			t2.Fatal(err)
+		}
+		check(t2, job.Results)
+	})
+}
+
+func TestValidateSeed(t *testing.T) {
+	testCases := []struct {
+		seed uint64
+		valid bool
+	}{
+		{seed: 0, valid: true},
+		{seed: 1, valid: true},
+		{seed: MaxSeed, valid: true},
+		{seed: MaxSeed + 1, valid: false},
+	}
+
+	for _, testCase := range testCases {
+		err := validateSeed(testCase.seed)
+		if (err == nil) != testCase.valid {
+			t.Errorf("expected seed %d to be valid: %v but got: %v", testCase.seed, testCase.valid, err)
+		}
+	}
+
+	client := newMockClient(t, jobsHandler(t, "job-1"), WithSeed(MaxSeed + 1))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+	if _, err := client.RunExperiment(context.Background(), testExpStr); err == nil {
+		t.Error("expected RunExperiment to reject the seed")
+	}
+	if err := client.RunJob(context.Background(), NewJob([]string{testExpStr}, 1, 3)); err == nil {
+		t.Error("expected RunJob to reject the seed")
+	}
+}
+
+func TestClient_RunQObj(t *testing.T) {
+	qobj := json.RawMessage(`{"qobj_id":"bell","type":"QASM","schema_version":"1.0.0","config":{"shots":1024,"memory_slots":2},"experiments":[{"instructions":[{"name":"h","qubits":[0]},{"name":"cx","qubits":[0,1]},{"name":"measure","qubits":[0,1],"memory":[0,1]}]}]}`)
+
+	var req struct {
+		CodeType string	`json:"codeType"`
+		Backend JobBackend	`json:"backend"`
+		QObject json.RawMessage	`json:"qObject"`
+		Qasms []JobQasm	`json:"qasms"`
+	}
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/Jobs" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"id": "job-1", "status": {"id": "RUNNING"}}`))
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	jobId, err := client.RunQObj(context.Background(), qobj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if jobId != "job-1" {
+		t.Errorf("expected job id job-1 but got %s", jobId)
+	}
+	if !bytes.Equal(req.QObject, qobj) {
+		t.Errorf("expected the QObj to be passed through verbatim but got: %s", req.QObject)
+	}
+	if req.CodeType != QObjCodeType || req.Backend.Name != DefaultBackend || req.Qasms != nil {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	t.Run("invalid", func(t2 *testing.T) {
+		if _, err := client.RunQObj(context.Background(), json.RawMessage(`{"qobj_id":`)); err == nil {
+			t2.Error("expected invalid JSON to be rejected")
+		}
+	})
+}
+
+func TestClient_Jobs_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	var submitted int
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/Jobs":
+			mu.Lock()
+			submitted++
+			jobId := fmt.Sprintf("job-%d", submitted)
+			mu.Unlock()
+			fmt.Fprintf(w, `{"id": "%s", "status": {"id": "RUNNING"}}`, jobId)
+		case r.URL.Path == "/Jobs":
+			w.Write([]byte(`[{"id": "job-1", "status": "COMPLETED", "shots": 1024}, {"id": "job-2", "status": "RUNNING"}]`))
+		default:
+			fmt.Fprintf(w, `{"id": "%s", "status": "COMPLETED", "shots": 1024}`, strings.TrimPrefix(r.URL.Path, "/Jobs/"))
+		}
+	}))
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	const n = 20
+	var wg sync.WaitGroup
+	jobs := make([]*Job, n)
+	for i := 0; i < n; i++ {
+		jobs[i] = NewJob([]string{testExpStr}, 1024, 3)
+
+		wg.Add(3)
+		go func(j *Job) {
+			defer wg.Done()
+			if err := client.RunJob(context.Background(), j); err != nil {
+				t.Error(err)
+			}
+		}(jobs[i])
+		go func(i int) {
+			defer wg.Done()
+			if _, err := client.GetJob(fmt.Sprintf("job-%d", i + 1)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+		go func(j *Job) {
+			defer wg.Done()
+			if _, err := client.GetJobsWithQuery(JobQuery{}); err != nil {
+				t.Error(err)
+			}
+			if _, err := json.Marshal(j); err != nil {
+				t.Error(err)
+			}
+		}(jobs[i])
+	}
+	wg.Wait()
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	for _, j := range jobs {
+		if client.jobs[j.Id] != j {
+			t.Errorf("expected job %s to be cached", j.Id)
+		}
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	testCases := []struct {
+		shots, experiments int
+		simulator bool
+		expected int
+	}{
+		{shots: 1024, experiments: 1, simulator: true, expected: 0},
+		{shots: 1, experiments: 1, expected: 3},
+		{shots: 0, experiments: 1, expected: 3},
+		{shots: 1024, experiments: 1, expected: 3},
+		{shots: 1025, experiments: 1, expected: 6},
+		{shots: 8192, experiments: 2, expected: 48},
+		{shots: 1024, experiments: 0, expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		if cost := estimateCost(testCase.shots, testCase.experiments, testCase.simulator); cost != testCase.expected {
+			t.Errorf("expected %d shots of %d experiments to cost %d credits but got %d", testCase.shots, testCase.experiments, testCase.expected, cost)
+		}
+	}
+}
+
+func TestClient_EstimateCost(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"credit": {"remaining": 10, "maxUserType": 15}}`))
+	}))
+	client.SetBackendCache(Backends{
+		DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true},
+		"ibmqx4": &Backend{Name: "ibmqx4"},
+	})
+
+	t.Run("simulator", func(t2 *testing.T) {
+		cost, err := client.EstimateCost(NewJob([]string{testExpStr}, 8192, 3), "")
+		if err != nil || cost != 0 {
+			t2.Errorf("expected the simulator to be free but got %d: %v", cost, err)
+		}
+	})
+
+	t.Run("affordable", func(t2 *testing.T) {
+		cost, err := client.EstimateCost(NewJob([]string{testExpStr, testExpStr}, 1024, 3), "ibmqx4")
+		if err != nil || cost != 6 {
+			t2.Errorf("expected the job to cost 6 credits but got %d: %v", cost, err)
+		}
+	})
+
+	t.Run("unaffordable", func(t2 *testing.T) {
+		cost, err := client.EstimateCost(NewJob([]string{testExpStr}, 4096, 3), "ibmqx4")
+		creditsErr, ok := err.(InsufficientCreditsErr)
+		if !ok {
+			t2.Fatalf("expected an InsufficientCreditsErr but got: %v", err)
+		}
+		if cost != 12 || creditsErr.Required != 12 || creditsErr.Available != 10 {
+			t2.Errorf("expected 12 credits required and 10 available but got %d, %d and %d", cost, creditsErr.Required, creditsErr.Available)
+		}
+	})
+
+	t.Run("alias", func(t2 *testing.T) {
+		client := newMockClient(t2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t2.Errorf("unexpected request to the API: %s", r.URL.Path)
+		}))
+		client.SetBackendCache(Backends{"ibmqx_qasm_simulator": &Backend{Name: "ibmqx_qasm_simulator", Simulator: true}})
+
+		cost, err := client.EstimateCost(NewJob([]string{testExpStr}, 8192, 3), "")
+		if err != nil || cost != 0 {
+			t2.Errorf("expected the default backend to resolve to the simulator but got %d: %v", cost, err)
+		}
+	})
+}
+
+func TestDefaultName(t *testing.T) {
+	// Both times would be named "Experiment #2024130945" without padding
+	first := time.Date(2024, time.January, 3, 9, 4, 5, 0, time.UTC)
+	second := first.Add(time.Second)
+
+	names := []string{defaultName(first), defaultName(second)}
+	if names[0] == names[1] {
+		t.Fatalf("expected distinct names a second apart but got %s twice", names[0])
+	}
+	if names[0] != "Experiment #20240103-090405" {
+		t.Errorf("unexpected name: %s", names[0])
+	}
+
+	for i, at := range []time.Time{first, second} {
+		parsed, err := time.Parse("Experiment #20060102-150405", names[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Equal(at) {
+			t.Errorf("expected %s to parse as %s but got %s", names[i], at, parsed)
+		}
+	}
+}
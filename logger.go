@@ -0,0 +1,18 @@
+package qiskit_api_go
+
+// Logger is the logger used for the internal logging of the connection and its clients
+// It is satisfied by most loggers, e.g. *logrus.Logger
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// nopLogger is the default Logger, which discards everything
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Infof(string, ...interface{}) {}
+func (nopLogger) Warnf(string, ...interface{}) {}
+func (nopLogger) Errorf(string, ...interface{}) {}
@@ -0,0 +1,67 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeLogger captures everything logged to it
+type fakeLogger struct {
+	mu sync.Mutex
+	lines []string
+}
+
+func (l *fakeLogger) logf(level, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, level + ": " + fmt.Sprintf(format, args...))
+}
+
+func (l *fakeLogger) Debugf(format string, args ...interface{}) { l.logf("debug", format, args...) }
+func (l *fakeLogger) Infof(format string, args ...interface{}) { l.logf("info", format, args...) }
+func (l *fakeLogger) Warnf(format string, args ...interface{}) { l.logf("warn", format, args...) }
+func (l *fakeLogger) Errorf(format string, args ...interface{}) { l.logf("error", format, args...) }
+
+func TestWithLogger(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id": "job-1"}`))
+	}))
+	defer srv.Close()
+
+	logger := &fakeLogger{}
+	conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(srv.URL), WithRetries(2), WithBackoff(time.Millisecond, time.Millisecond), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(conn)
+	client.SetBackendCache(Backends{DefaultBackend: &Backend{Name: DefaultBackend, Simulator: true}})
+
+	if err := client.RunJob(context.Background(), NewJob([]string{testExpStr}, MaxShots+1, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"warn: shots were more than the maximum", "debug: retrying"}
+	if len(logger.lines) != len(expected) {
+		t.Fatalf("expected %d log lines but got: %v", len(expected), logger.lines)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(logger.lines[i], prefix) {
+			t.Errorf("expected log line %d to start with %q but got %q", i, prefix, logger.lines[i])
+		}
+	}
+	if strings.Contains(strings.Join(logger.lines, "\n"), "test-token") {
+		t.Error("expected the access token to be redacted from the logs")
+	}
+}
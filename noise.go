@@ -0,0 +1,44 @@
+package qiskit_api_go
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NoiseModel represents the noise a simulator applies while running a circuit
+type NoiseModel struct {
+	// GateErrors is the depolarizing error probability of each gate, by gate name
+	GateErrors map[string]float64	`json:"gate_errors,omitempty"`
+	// ReadoutError is the probability of a measurement being flipped
+	ReadoutError float64	`json:"readout_error,omitempty"`
+}
+
+// validate checks the error rates are probabilities and that every gate with an error rate is used by the circuits
+func (nm NoiseModel) validate(qasms ...string) error {
+	if nm.ReadoutError < 0 || nm.ReadoutError > 1 {
+		return ApiErr{usrMsg: fmt.Sprintf("invalid noise model readout error (%g), it must be between 0 and 1", nm.ReadoutError)}
+	}
+
+	used := make(map[string]bool)
+	for _, qasm := range qasms {
+		for gate := range circuitGates(qasm) {
+			used[gate] = true
+		}
+	}
+
+	gates := make([]string, 0, len(nm.GateErrors))
+	for gate := range nm.GateErrors {
+		gates = append(gates, gate)
+	}
+	sort.Strings(gates)
+
+	for _, gate := range gates {
+		if p := nm.GateErrors[gate]; p < 0 || p > 1 {
+			return ApiErr{usrMsg: fmt.Sprintf("invalid noise model error (%g) for gate %s, it must be between 0 and 1", p, gate)}
+		}
+		if !used[gate] {
+			return ApiErr{usrMsg: fmt.Sprintf("noise model has an error for gate %s, which the circuit does not use", gate)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func TestClient_RunExperiment_NoiseModel(t *testing.T) {
+	model := NoiseModel{GateErrors: map[string]float64{"h": 0.001, "cx": 0.01}, ReadoutError: 0.02}
+	bell, err := NewCircuitBuilder().H(0).CX(0, 1).Measure(0, 0).Measure(1, 1).QASM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var submitted map[string]json.RawMessage
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&submitted); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"id": "exec-1"}`))
+	}), WithNoiseModel(model))
+
+	if _, err := client.RunExperiment(context.Background(), bell); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent NoiseModel
+	if err := json.Unmarshal(submitted["noise_model"], &sent); err != nil {
+		t.Fatalf("expected the noise model to be submitted: %s", err)
+	}
+	if sent.ReadoutError != 0.02 || sent.GateErrors["h"] != 0.001 || sent.GateErrors["cx"] != 0.01 {
+		t.Errorf("unexpected submitted noise model: %+v", sent)
+	}
+
+	t.Run("unused_gate", func(t2 *testing.T) {
+		invalid := NoiseModel{GateErrors: map[string]float64{"u3": 0.001}}
+		if _, err := client.RunExperiment(context.Background(), bell, WithNoiseModel(invalid)); err == nil {
+			t2.Error("expected an error for a gate the circuit does not use")
+		}
+	})
+
+	t.Run("real_backend", func(t2 *testing.T) {
+		if _, err := client.RunExperiment(context.Background(), bell, WithBackend("ibmqx2")); err == nil {
+			t2.Error("expected an error for a noise model on a real backend")
+		}
+	})
+}
@@ -0,0 +1,298 @@
+package qiskit_api_go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"golang.org/x/crypto/md4"
+)
+
+// ntlmSignature starts every NTLM message
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmFlags are the negotiate flags sent by the client: unicode, request target, NTLM, always sign and extended session security
+const ntlmFlags uint32 = 0x00088207
+
+// ntlmNegotiate returns the NTLM Type 1 message, which starts the handshake
+func ntlmNegotiate() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmFlags)
+	return msg
+}
+
+// ntlmChallenge is the NTLM Type 2 message, sent by the proxy in response to the Type 1 message
+type ntlmChallenge struct {
+	flags uint32
+	serverChallenge []byte
+	targetInfo []byte
+}
+
+// parseNtlmChallenge parses the NTLM Type 2 message
+func parseNtlmChallenge(msg []byte) (ntlmChallenge, error) {
+	if len(msg) < 48 || !bytes.Equal(msg[:8], ntlmSignature) || binary.LittleEndian.Uint32(msg[8:]) != 2 {
+		return ntlmChallenge{}, fmt.Errorf("invalid NTLM challenge message")
+	}
+
+	c := ntlmChallenge{
+		flags: binary.LittleEndian.Uint32(msg[20:]),
+		serverChallenge: msg[24:32],
+	}
+
+	infoLen := int(binary.LittleEndian.Uint16(msg[40:]))
+	infoOffset := int(binary.LittleEndian.Uint32(msg[44:]))
+	if infoOffset+infoLen > len(msg) {
+		return ntlmChallenge{}, fmt.Errorf("invalid NTLM challenge message, target info is out of bounds")
+	}
+	c.targetInfo = msg[infoOffset : infoOffset+infoLen]
+	return c, nil
+}
+
+// ntlmAuthenticate returns the NTLM Type 3 message, which answers the challenge with an NTLMv2 response
+// The username may be qualified with its domain, i.e. DOMAIN\user
+func ntlmAuthenticate(c ntlmChallenge, username, password string) ([]byte, error) {
+	domain, user := "", username
+	if i := strings.Index(username, `\`); i >= 0 {
+		domain, user = username[:i], username[i+1:]
+	}
+
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+
+	ntResp, lmResp := ntlmV2Response(c, user, domain, password, clientChallenge, time.Now())
+
+	fields := [][]byte{lmResp, ntResp, ntlmUnicode(domain), ntlmUnicode(user), nil, nil}
+	msg := make([]byte, 64)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+
+	offset := len(msg)
+	for i, field := range fields {
+		binary.LittleEndian.PutUint16(msg[12+i*8:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(msg[14+i*8:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(msg[16+i*8:], uint32(offset))
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], (ntlmFlags & c.flags) | 0x00000001)
+
+	for _, field := range fields {
+		msg = append(msg, field...)
+	}
+	return msg, nil
+}
+
+// ntlmV2Response computes the NTLMv2 and LMv2 responses to the challenge
+func ntlmV2Response(c ntlmChallenge, user, domain, password string, clientChallenge []byte, now time.Time) (ntResp, lmResp []byte) {
+	hash := md4.New()
+	hash.Write(ntlmUnicode(password))
+	ntowf := ntlmHmac(hash.Sum(nil), ntlmUnicode(strings.ToUpper(user) + domain))
+
+	// Windows timestamps count 100ns intervals since 1601
+	timestamp := make([]byte, 8)
+	binary.LittleEndian.PutUint64(timestamp, uint64(now.UnixNano() / 100 + 116444736000000000))
+
+	var blob bytes.Buffer
+	blob.Write([]byte{1, 1, 0, 0, 0, 0, 0, 0})
+	blob.Write(timestamp)
+	blob.Write(clientChallenge)
+	blob.Write([]byte{0, 0, 0, 0})
+	blob.Write(c.targetInfo)
+	blob.Write([]byte{0, 0, 0, 0})
+
+	proof := ntlmHmac(ntowf, c.serverChallenge, blob.Bytes())
+	ntResp = append(proof, blob.Bytes()...)
+	lmResp = append(ntlmHmac(ntowf, c.serverChallenge, clientChallenge), clientChallenge...)
+	return ntResp, lmResp
+}
+
+func ntlmHmac(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// ntlmUnicode encodes the string as UTF-16LE, as NTLM expects
+func ntlmUnicode(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(codes))
+	for i, code := range codes {
+		binary.LittleEndian.PutUint16(b[2*i:], code)
+	}
+	return b
+}
+
+// ntlmTunnel dials the proxy and opens a tunnel to addr with CONNECT, authenticating against the proxy with NTLM
+// An https proxy is spoken to over TLS, configured by tlsConfig
+func ntlmTunnel(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error), proxy *url.URL, tlsConfig *tls.Config, addr, username, password string) (net.Conn, error) {
+	conn, err := dial(ctx, "tcp", proxyAddr(proxy))
+	if err != nil {
+		return nil, err
+	}
+
+	if proxy.Scheme == "https" {
+		if conn, err = tlsHandshake(ctx, conn, tlsConfig, proxy.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	br := bufio.NewReader(conn)
+	connect := func(auth []byte) (*http.Response, error) {
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL: &url.URL{Opaque: addr},
+			Host: addr,
+			Header: http.Header{"Proxy-Authorization": {"NTLM " + base64.StdEncoding.EncodeToString(auth)}},
+		}
+		if err := req.Write(conn); err != nil {
+			return nil, err
+		}
+
+		resp, err := http.ReadResponse(br, req)
+		if err != nil {
+			return nil, err
+		}
+
+		// The tunnel follows a successful CONNECT, so only the body of a failed one is read
+		if resp.StatusCode != http.StatusOK {
+			drainBody(resp)
+		}
+		return resp, nil
+	}
+
+	// Negotiate, and answer the challenge the proxy responds with
+	resp, err := connect(ntlmNegotiate())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	challenge, err := ntlmChallengeHeader(resp)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	auth, err := ntlmAuthenticate(challenge, username, password)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err = connect(auth)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, CredentialsErr{NewApiErr("the proxy rejected the NTLM credentials", fmt.Sprintf("CONNECT %s returned %s", addr, resp.Status), nil)}
+	}
+
+	return conn, nil
+}
+
+// ntlmChallengeHeader parses the NTLM challenge of a proxy response
+func ntlmChallengeHeader(resp *http.Response) (ntlmChallenge, error) {
+	if resp.StatusCode != http.StatusProxyAuthRequired {
+		return ntlmChallenge{}, fmt.Errorf("expected the proxy to respond with an NTLM challenge but got %s", resp.Status)
+	}
+
+	for _, header := range resp.Header.Values("Proxy-Authenticate") {
+		if !strings.HasPrefix(header, "NTLM ") {
+			continue
+		}
+
+		msg, err := base64.StdEncoding.DecodeString(strings.TrimSpace(header[len("NTLM "):]))
+		if err != nil {
+			return ntlmChallenge{}, err
+		}
+		return parseNtlmChallenge(msg)
+	}
+	return ntlmChallenge{}, fmt.Errorf("the proxy did not send an NTLM challenge")
+}
+
+// proxyAddr returns the host:port of the proxy, defaulting the port by its scheme
+func proxyAddr(proxy *url.URL) string {
+	if proxy.Port() != "" {
+		return proxy.Host
+	}
+	if proxy.Scheme == "https" {
+		return net.JoinHostPort(proxy.Hostname(), "443")
+	}
+	return net.JoinHostPort(proxy.Hostname(), "80")
+}
+
+// setNtlmDialers configures the transport to tunnel requests through the proxies, authenticating against them with NTLM
+// Requests with a scheme which has no proxy configured are sent directly.
+func setNtlmDialers(transport *http.Transport, proxies map[string]*url.URL, username, password string) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport.Proxy = nil
+
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		proxy, ok := proxies["http"]
+		if !ok {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return ntlmTunnel(ctx, dialer.DialContext, proxy, transport.TLSClientConfig, addr, username, password)
+	}
+
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var conn net.Conn
+		var err error
+		if proxy, ok := proxies["https"]; ok {
+			conn, err = ntlmTunnel(ctx, dialer.DialContext, proxy, transport.TLSClientConfig, addr, username, password)
+		} else {
+			conn, err = dialer.DialContext(ctx, network, addr)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		host, _, _ := net.SplitHostPort(addr)
+		return tlsHandshake(ctx, conn, transport.TLSClientConfig, host)
+	}
+}
+
+// tlsHandshake starts TLS over the connection, verifying serverName unless the config names a server itself
+// The connection is closed if the handshake fails
+func tlsHandshake(ctx context.Context, conn net.Conn, base *tls.Config, serverName string) (net.Conn, error) {
+	var cfg *tls.Config
+	if base != nil {
+		cfg = base.Clone()
+	} else {
+		cfg = &tls.Config{}
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = serverName
+	}
+
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
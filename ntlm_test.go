@@ -0,0 +1,217 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ntlmTestChallenge builds an NTLM Type 2 message with the given server challenge and target info
+func ntlmTestChallenge(serverChallenge, targetInfo []byte) []byte {
+	msg := make([]byte, 48)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint32(msg[20:], ntlmFlags | 0x00800000)
+	copy(msg[24:], serverChallenge)
+	binary.LittleEndian.PutUint16(msg[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(msg[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(msg[44:], 48)
+	return append(msg, targetInfo...)
+}
+
+// ntlmTestField returns the field of an NTLM Type 3 message at the given index
+func ntlmTestField(msg []byte, i int) []byte {
+	l := int(binary.LittleEndian.Uint16(msg[12+i*8:]))
+	offset := int(binary.LittleEndian.Uint32(msg[16+i*8:]))
+	return msg[offset : offset+l]
+}
+
+func TestWithProxies_NTLM(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api": true}`))
+	}))
+	defer target.Close()
+
+	serverChallenge := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	targetInfo := []byte{2, 0, 12, 0, 'D', 0, 'O', 0, 'M', 0, 'A', 0, 'I', 0, 'N', 0, 0, 0, 0, 0}
+
+	var mu sync.Mutex
+	var steps []string
+	step := func(s string) {
+		mu.Lock()
+		steps = append(steps, s)
+		mu.Unlock()
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+
+		// Type 1
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		msg, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(req.Header.Get("Proxy-Authorization"), "NTLM "))
+		if req.Method != http.MethodConnect || len(msg) < 12 || !bytes.Equal(msg[:8], ntlmSignature) || binary.LittleEndian.Uint32(msg[8:]) != 1 {
+			t.Errorf("expected a CONNECT with an NTLM negotiate message but got %s %q", req.Method, req.Header.Get("Proxy-Authorization"))
+			return
+		}
+		step("negotiate " + req.Host)
+
+		// Type 2
+		challenge := base64.StdEncoding.EncodeToString(ntlmTestChallenge(serverChallenge, targetInfo))
+		io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM " + challenge + "\r\nContent-Length: 0\r\n\r\n")
+
+		// Type 3
+		req, err = http.ReadRequest(br)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		msg, _ = base64.StdEncoding.DecodeString(strings.TrimPrefix(req.Header.Get("Proxy-Authorization"), "NTLM "))
+		if len(msg) < 64 || !bytes.Equal(msg[:8], ntlmSignature) || binary.LittleEndian.Uint32(msg[8:]) != 3 {
+			t.Errorf("expected an NTLM authenticate message but got %q", req.Header.Get("Proxy-Authorization"))
+			return
+		}
+
+		domain, user, ntResp := ntlmTestField(msg, 2), ntlmTestField(msg, 3), ntlmTestField(msg, 1)
+		if !bytes.Equal(domain, ntlmUnicode("CORP")) || !bytes.Equal(user, ntlmUnicode("alice")) {
+			t.Errorf("unexpected domain %q and user %q", domain, user)
+		}
+
+		// Recompute the response from the blob the client sent, which must match for the right password
+		blob := ntResp[16:]
+		c := ntlmChallenge{serverChallenge: serverChallenge, targetInfo: blob[28 : len(blob)-4]}
+		expected, _ := ntlmV2Response(c, "alice", "CORP", "secret", blob[16:24], time.Unix(0, (int64(binary.LittleEndian.Uint64(blob[8:16])) - 116444736000000000) * 100))
+		if !bytes.Equal(ntResp, expected) || !bytes.Equal(c.targetInfo, targetInfo) {
+			t.Error("the NTLMv2 response does not match the password")
+			io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nContent-Length: 0\r\n\r\n")
+			return
+		}
+		step("authenticate " + req.Host)
+
+		// Tunnel
+		io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n")
+		upstream, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer upstream.Close()
+		go io.Copy(upstream, br)
+		io.Copy(conn, upstream)
+	}()
+
+	conn, err := Dial(WithAccessInfo("test-token", "test-user"), WithApiUrl(target.URL), WithRetries(1),
+		WithProxies(map[string]string{"http": "http://" + ln.Addr().String()}, `CORP\alice`, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := NewClient(conn).APIStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.ApiUp {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	targetHost := strings.TrimPrefix(target.URL, "http://")
+	mu.Lock()
+	defer mu.Unlock()
+	if len(steps) != 2 || steps[0] != "negotiate " + targetHost || steps[1] != "authenticate " + targetHost {
+		t.Errorf("unexpected NTLM negotiation: %v", steps)
+	}
+}
+
+func TestNtlmTunnel_HttpsProxy(t *testing.T) {
+	// The TLS server only lends its certificate to the proxy, and a client config which trusts it
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln = tls.NewListener(ln, srv.TLS)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+
+		if _, err := http.ReadRequest(br); err != nil {
+			t.Error(err)
+			return
+		}
+		challenge := base64.StdEncoding.EncodeToString(ntlmTestChallenge([]byte{1, 2, 3, 4, 5, 6, 7, 8}, nil))
+		io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM " + challenge + "\r\nContent-Length: 0\r\n\r\n")
+
+		if _, err := http.ReadRequest(br); err != nil {
+			t.Error(err)
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n")
+
+		// Echo what is sent through the tunnel
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		io.WriteString(conn, line)
+	}()
+
+	proxy, err := url.Parse("https://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+
+	var dialer net.Dialer
+	conn, err := ntlmTunnel(context.Background(), dialer.DialContext, proxy, tlsConfig, "api.example.com:443", `CORP\alice`, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, ok := conn.(*tls.Conn); !ok {
+		t.Errorf("expected the proxy to be spoken to over TLS but got a %T", conn)
+	}
+	io.WriteString(conn, "ping\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "ping\n" {
+		t.Errorf("expected the tunnel to be opened but read %q", line)
+	}
+}
@@ -0,0 +1,176 @@
+package qiskit_api_go
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	qasmCommentRegex = regexp.MustCompile(`//.*`)
+	registerDeclRegex = regexp.MustCompile(`^(qreg|creg)\s+(\w+)\s*\[\s*(\d+)\s*\]$`)
+	registerRefRegex = regexp.MustCompile(`\b([A-Za-z_]\w*)\s*\[\s*(\d+)\s*\]`)
+)
+
+// qasmKeywords are the statements of a circuit which aren't gates
+var qasmKeywords = map[string]bool{
+	"OPENQASM": true, "IBMQASM": true, "include": true, "qreg": true, "creg": true, "measure": true,
+	"barrier": true, "reset": true, "gate": true, "opaque": true, "if": true,
+}
+
+// DefaultQASMVersion is the OpenQASM version circuits are assumed to be written in
+const DefaultQASMVersion = "2.0"
+
+// qasmCodeTypes maps the supported OpenQASM versions to the code type the API expects for them
+var qasmCodeTypes = map[string]string{
+	"2.0": "QASM2",
+	"3.0": "QASM3",
+}
+
+// qasmCodeType returns the code type of the given OpenQASM version
+func qasmCodeType(version string) (string, error) {
+	codeType, ok := qasmCodeTypes[version]
+	if !ok {
+		return "", ApiErr{usrMsg: fmt.Sprintf("unsupported OpenQASM version \"%s\", expected 2.0 or 3.0", version)}
+	}
+	return codeType, nil
+}
+
+// stripQasmHeaders removes the version headers of a circuit, as the API expects circuits without them
+func stripQasmHeaders(qasm, version string) string {
+	if version == "3.0" {
+		qasm = strings.Replace(qasm, "OPENQASM 3.0;", "", -1)
+		return strings.Replace(qasm, "OPENQASM 3;", "", -1)
+	}
+
+	qasm = strings.Replace(qasm, "IBMQASM 2.0;", "", -1)
+	return strings.Replace(qasm, "OPENQASM 2.0;", "", -1)
+}
+
+var gateNameRegex = regexp.MustCompile(`^([A-Za-z_]\w*)`)
+
+// circuitGates returns the names of the gates applied by a circuit
+func circuitGates(qasm string) map[string]bool {
+	qasm = qasmCommentRegex.ReplaceAllString(qasm, "")
+
+	gates := make(map[string]bool)
+	for _, stmt := range strings.Split(qasm, ";") {
+		stmt = strings.TrimSpace(stmt)
+
+		// Skip over gate definitions, only the statements after them apply gates
+		if i := strings.LastIndex(stmt, "}"); i >= 0 {
+			stmt = strings.TrimSpace(stmt[i+1:])
+		}
+
+		m := gateNameRegex.FindStringSubmatch(stmt)
+		if m == nil || qasmKeywords[m[1]] {
+			continue
+		}
+		gates[m[1]] = true
+	}
+	return gates
+}
+
+var (
+	qasmHeaderRegex = regexp.MustCompile(`^(?:OPENQASM|IBMQASM)\s+(\d+)(?:\.\d+)?\s*;`)
+	qasm3DeclRegex = regexp.MustCompile(`^(?:qubit|bit)\s*(?:\[\s*(\d+)\s*\])?\s+(\w+)$`)
+)
+
+// ValidateQASM performs basic checks of a circuit, so malformed circuits are caught before they are submitted
+// The circuit must start with a recognized version header, have balanced braces and declare a register,
+// and every register reference must be within the declared size of the register, otherwise a RegisterSizeErr is returned
+func ValidateQASM(qasm string) error {
+	stripped := strings.TrimSpace(qasmCommentRegex.ReplaceAllString(qasm, ""))
+
+	m := qasmHeaderRegex.FindStringSubmatch(stripped)
+	if m == nil {
+		return ApiErr{usrMsg: "qasm must start with a version header, e.g. OPENQASM 2.0;"}
+	}
+
+	depth := 0
+	for _, r := range stripped {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 {
+		return ApiErr{usrMsg: "qasm has unbalanced braces"}
+	}
+
+	declRegex := registerDeclRegex
+	if m[1] == "3" {
+		declRegex = qasm3DeclRegex
+	}
+	declared := false
+	for _, stmt := range strings.Split(stripped, ";") {
+		if declRegex.MatchString(strings.TrimSpace(stmt)) {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		return ApiErr{usrMsg: "qasm must declare at least one register"}
+	}
+
+	return validateRegisters(stripped)
+}
+
+// validateRegisters checks that every indexed register reference is within the declared size of the register
+func validateRegisters(qasm string) error {
+	qasm = qasmCommentRegex.ReplaceAllString(qasm, "")
+
+	sizes := make(map[string]int)
+	for _, stmt := range strings.Split(qasm, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if m := registerDeclRegex.FindStringSubmatch(stmt); m != nil {
+			size, err := strconv.Atoi(m[3])
+			if err != nil {
+				return err
+			}
+			sizes[m[2]] = size
+			continue
+		}
+		// OpenQASM 3 declares registers as qubit[n] q or bit[n] c, where a missing size declares a single bit
+		if m := qasm3DeclRegex.FindStringSubmatch(stmt); m != nil {
+			size := 1
+			if m[1] != "" {
+				var err error
+				if size, err = strconv.Atoi(m[1]); err != nil {
+					return err
+				}
+			}
+			sizes[m[2]] = size
+			continue
+		}
+
+		for _, m := range registerRefRegex.FindAllStringSubmatch(stmt, -1) {
+			size, declared := sizes[m[1]]
+			if !declared {
+				continue
+			}
+
+			index, err := strconv.Atoi(m[2])
+			if err != nil {
+				return err
+			}
+
+			if index >= size {
+				return RegisterSizeErr{
+					ApiErr: ApiErr{usrMsg: fmt.Sprintf("%s[%d] is out of range, register %s has a size of %d", m[1], index, m[1], size)},
+					Register: m[1],
+					Index: index,
+					Size: size,
+				}
+			}
+		}
+	}
+
+	return nil
+}
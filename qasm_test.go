@@ -0,0 +1,104 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"context"
+	"net/http"
+)
+
+func TestValidateRegisters(t *testing.T) {
+	if err := validateRegisters(testExpStr); err != nil {
+		t.Errorf("expected valid qasm but got: %s", err)
+	}
+
+	qasm := `OPENQASM 2.0;
+include "qelib1.inc";
+qreg q[3];
+creg c[3];
+h q[0];
+cx q[0],q[5]; // out of range
+measure q -> c;`
+
+	err := validateRegisters(qasm)
+	regErr, ok := err.(RegisterSizeErr)
+	if !ok {
+		t.Fatalf("expected a RegisterSizeErr but got: %v", err)
+	}
+
+	if regErr.Register != "q" || regErr.Index != 5 || regErr.Size != 3 {
+		t.Errorf("unexpected register size error: %+v", regErr)
+	}
+
+	err = validateRegisters("OPENQASM 3.0;\nqubit[2] q;\nbit[2] c;\nh q[5];")
+	regErr, ok = err.(RegisterSizeErr)
+	if !ok {
+		t.Fatalf("expected a RegisterSizeErr for qasm 3 but got: %v", err)
+	}
+
+	if regErr.Register != "q" || regErr.Index != 5 || regErr.Size != 2 {
+		t.Errorf("unexpected register size error: %+v", regErr)
+	}
+}
+
+func TestValidateQASM(t *testing.T) {
+	bell, err := NewCircuitBuilder().H(0).CX(0, 1).Measure(0, 0).Measure(1, 1).QASM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		qasm string
+		valid bool
+	}{
+		{name: "experiment", qasm: testExpStr, valid: true},
+		{name: "builder", qasm: bell, valid: true},
+		{name: "gate_definition", qasm: "OPENQASM 2.0;\nqreg q[2];\ngate bell a,b { h a; cx a,b; }\nbell q[0],q[1];", valid: true},
+		{name: "qasm3", qasm: "OPENQASM 3.0;\nqubit[2] q;\nbit[2] c;\nh q[0];", valid: true},
+		{name: "comment_before_header", qasm: "// bell state\nOPENQASM 2.0;\nqreg q[2];", valid: true},
+		{name: "no_header", qasm: "qreg q[1];\nx q[0];"},
+		{name: "unknown_header", qasm: "QASM 2.0;\nqreg q[1];"},
+		{name: "unbalanced_braces", qasm: "OPENQASM 2.0;\nqreg q[2];\ngate bell a,b { h a; cx a,b;\nbell q[0],q[1];"},
+		{name: "closed_before_opened", qasm: "OPENQASM 2.0;\nqreg q[2];\n} gate bell a,b { h a;"},
+		{name: "no_registers", qasm: "OPENQASM 2.0;\ninclude \"qelib1.inc\";"},
+		{name: "out_of_range", qasm: "OPENQASM 2.0;\nqreg q[1];\ncreg c[1];\nmeasure q[1] -> c[0];"},
+		{name: "qasm3_out_of_range", qasm: "OPENQASM 3.0;\nqubit[2] q;\nbit[2] c;\nh q[5];"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			err := ValidateQASM(testCase.qasm)
+			if testCase.valid && err != nil {
+				t2.Errorf("expected valid qasm but got: %s", err)
+			}
+			if !testCase.valid && err == nil {
+				t2.Error("expected invalid qasm to be rejected")
+			}
+		})
+	}
+
+	if _, ok := ValidateQASM("OPENQASM 2.0;\nqreg q[1];\ncreg c[1];\nmeasure q[1] -> c[0];").(RegisterSizeErr); !ok {
+		t.Error("expected a RegisterSizeErr for an out of range register")
+	}
+}
+
+func TestClient_RunExperiment_Validation(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to the API: %s", r.URL.Path)
+	}), WithValidation(true))
+
+	_, err := client.RunExperiment(context.Background(), "OPENQASM 2.0;\nqreg q[1];\ncreg c[1];\nmeasure q[1] -> c[0];")
+	if _, ok := err.(RegisterSizeErr); !ok {
+		t.Errorf("expected a RegisterSizeErr but got: %v", err)
+	}
+}
+
+func TestClient_RunExperiment_WithoutValidation(t *testing.T) {
+	client := newMockClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "exec-1"}`))
+	}), WithValidation(false))
+
+	if _, err := client.RunExperiment(context.Background(), "x q[0];"); err != nil {
+		t.Errorf("expected unvalidated qasm to be submitted but got: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package qiskit_api_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// replayResponse is a recorded API response
+type replayResponse struct {
+	Status int				`json:"status"`
+	Header http.Header		`json:"header,omitempty"`
+	Body string				`json:"body"`
+}
+
+// replayTransport records API responses to dir, and replays them instead of hitting the API once recorded
+// Each request is keyed by its method, path and query, without the access token, and
+// repeated requests are answered with the recorded responses in order.
+// Login requests are never recorded, so no credentials end up in the recordings.
+type replayTransport struct {
+	dir string
+	apiPath string
+	base http.RoundTripper
+
+	mu sync.Mutex
+	seen map[string]int
+	recorded map[string][]replayResponse
+}
+
+func newReplayTransport(dir, apiUrl string, base http.RoundTripper) *replayTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	apiPath := ""
+	if u, err := url.Parse(apiUrl); err == nil {
+		apiPath = strings.Trim(u.Path, "/")
+	}
+
+	return &replayTransport{
+		dir: dir,
+		apiPath: apiPath,
+		base: base,
+		seen: make(map[string]int),
+		recorded: make(map[string][]replayResponse),
+	}
+}
+
+var replayFileRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// replayFile returns the recording file of the request
+// The API URL path is left out, so the same recordings can be replayed against any API URL
+func (rt *replayTransport) replayFile(req *http.Request) string {
+	q := req.URL.Query()
+	q.Del("access_token")
+
+	path := strings.TrimPrefix(strings.Trim(req.URL.Path, "/"), rt.apiPath)
+	key := req.Method + "_" + strings.Trim(path, "/")
+	if query := q.Encode(); query != "" {
+		key += "_" + query
+	}
+	return filepath.Join(rt.dir, strings.Trim(replayFileRegex.ReplaceAllString(key, "_"), "_") + ".json")
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (rt *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.Path, "users/login") {
+		return rt.base.RoundTrip(req)
+	}
+
+	file := rt.replayFile(req)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	n := rt.seen[file]
+	rt.seen[file]++
+
+	// Replay an existing recording
+	if b, err := ioutil.ReadFile(file); err == nil {
+		var responses []replayResponse
+		if err = json.Unmarshal(b, &responses); err != nil {
+			return nil, err
+		}
+
+		if len(responses) > 0 && (n < len(responses) || rt.recorded[file] == nil) {
+			if n >= len(responses) {
+				n = len(responses) - 1
+			}
+			return responses[n].response(req), nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Otherwise, record the real response
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recordings are kept readable, so compressed responses are recorded decompressed
+	if err := decompress(resp); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := replayResponse{Status: resp.StatusCode, Header: resp.Header, Body: string(body)}
+	rt.recorded[file] = append(rt.recorded[file], recorded)
+
+	b, err := json.MarshalIndent(rt.recorded[file], "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(rt.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(file, b, 0644); err != nil {
+		return nil, err
+	}
+
+	return recorded.response(req), nil
+}
+
+// response rebuilds the recorded response for the request
+func (r replayResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status: http.StatusText(r.Status),
+		StatusCode: r.Status,
+		Proto: "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: r.Header,
+		Body: ioutil.NopCloser(bytes.NewReader([]byte(r.Body))),
+		ContentLength: int64(len(r.Body)),
+		Request: req,
+	}
+}
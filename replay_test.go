@@ -0,0 +1,68 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+func TestWithReplay(t *testing.T) {
+	body, err := ioutil.ReadFile(filepath.Join(replayDir, "GET_Backends.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded []replayResponse
+	if err = json.Unmarshal(body, &recorded); err != nil {
+		t.Fatal(err)
+	}
+
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(recorded[0].Body))
+	}))
+	dir := t.TempDir()
+
+	dial := func() *Client {
+		conn, err := Dial(WithAccessInfo("secret-token", "test-user"), WithApiUrl(srv.URL + "/api"), WithRetries(1), WithReplay(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewClient(conn)
+	}
+
+	// First run records the live responses
+	live, err := dial().AvailableBackends()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected the API to be called once while recording but got %d calls", hits)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "GET_Backends.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret-token") {
+		t.Error("expected the access token to be left out of the recording")
+	}
+
+	// Later runs replay them without calling the API
+	srv.Close()
+	replayed, err := dial().AvailableBackends()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hits != 1 {
+		t.Errorf("expected no API calls while replaying but got %d", hits-1)
+	}
+	if !reflect.DeepEqual(live, replayed) {
+		t.Errorf("expected replayed backends to match the recorded ones\nlive: %v\nreplayed: %v", live, replayed)
+	}
+}
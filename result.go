@@ -0,0 +1,121 @@
+package qiskit_api_go
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ShotsTolerance is the fraction of the shots the total counts of a result may be off by and still be valid
+const ShotsTolerance = 0.005
+
+// Validate checks that the total counts of the result match the expected shots, within ShotsTolerance
+// The measured values are either probabilities, in which case they are scaled by the expected shots, or counts
+func (r ExpResult) Validate(expectedShots int) error {
+	values := r.Result.Measure.Values
+	if len(values) == 0 {
+		return ApiErr{usrMsg: "result has no measurements"}
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+
+	total := sum
+	if sum <= 1+ShotsTolerance {
+		total = sum * float64(expectedShots)
+	}
+
+	tolerance := math.Max(1, ShotsTolerance*float64(expectedShots))
+	if math.Abs(total-float64(expectedShots)) > tolerance {
+		return ApiErr{usrMsg: fmt.Sprintf("result counts total %g shots but %d shots were requested", total, expectedShots)}
+	}
+
+	if len(r.Memory) > 0 && len(r.Memory) != expectedShots {
+		return ApiErr{usrMsg: fmt.Sprintf("result memory has %d shots but %d shots were requested", len(r.Memory), expectedShots)}
+	}
+	return nil
+}
+
+// Histogram returns the probability of each measured bitstring of the result
+// nil is returned if the result is malformed, use ParseHistogram to get the reason.
+func (r ExpResult) Histogram() map[string]float64 {
+	hist, _ := r.ParseHistogram()
+	return hist
+}
+
+// ParseHistogram is Histogram, but returns an error if the measured labels and values don't line up
+func (r ExpResult) ParseHistogram() (map[string]float64, error) {
+	labels, values := r.Result.Measure.Labels, r.Result.Measure.Values
+	if len(labels) != len(values) {
+		return nil, ApiErr{usrMsg: fmt.Sprintf("result has %d measured labels but %d values", len(labels), len(values))}
+	}
+
+	hist := make(map[string]float64, len(labels))
+	for i, label := range labels {
+		hist[label] += values[i]
+	}
+	return hist, nil
+}
+
+// Counts returns the approximate number of shots each bitstring was measured in, given the shots the result was run with
+// Due to rounding, the counts may not add up to exactly shots. nil is returned if the result is malformed.
+func (r ExpResult) Counts(shots int) map[string]int {
+	hist := r.Histogram()
+	if hist == nil {
+		return nil
+	}
+
+	counts := make(map[string]int, len(hist))
+	for label, p := range hist {
+		counts[label] = int(math.Round(p * float64(shots)))
+	}
+	return counts
+}
+
+// CountsExact is Counts, but the counts always add up to exactly shots
+// The probabilities are normalized and rounded with the largest remainder method, breaking ties by bitstring.
+// nil is returned if the result is malformed or has no measured probability.
+func (r ExpResult) CountsExact(shots int) map[string]int {
+	hist := r.Histogram()
+	if hist == nil {
+		return nil
+	}
+
+	var sum float64
+	for _, p := range hist {
+		sum += p
+	}
+	if sum <= 0 {
+		return nil
+	}
+
+	type remainder struct {
+		label string
+		frac float64
+	}
+
+	counts := make(map[string]int, len(hist))
+	remainders := make([]remainder, 0, len(hist))
+	left := shots
+	for label, p := range hist {
+		exact := p / sum * float64(shots)
+		count := int(math.Floor(exact))
+		counts[label] = count
+		remainders = append(remainders, remainder{label: label, frac: exact - float64(count)})
+		left -= count
+	}
+
+	sort.Slice(remainders, func(i, j int) bool {
+		if remainders[i].frac != remainders[j].frac {
+			return remainders[i].frac > remainders[j].frac
+		}
+		return remainders[i].label < remainders[j].label
+	})
+	for i := 0; left > 0; i = (i + 1) % len(remainders) {
+		counts[remainders[i].label]++
+		left--
+	}
+	return counts
+}
@@ -0,0 +1,114 @@
+package qiskit_api_go
+
+import (
+	"testing"
+	"encoding/json"
+	"reflect"
+)
+
+func newTestResult(labels []string, values []float64) ExpResult {
+	var r ExpResult
+	r.Result.Measure.Labels = labels
+	r.Result.Measure.Values = values
+	return r
+}
+
+func TestExpResult_Validate(t *testing.T) {
+	testCases := []struct {
+		name string
+		values []float64
+		valid bool
+	}{
+		{name: "probabilities", values: []float64{0.5, 0.25, 0.25}, valid: true},
+		{name: "rounded_probabilities", values: []float64{0.333, 0.333, 0.333}, valid: true},
+		{name: "counts", values: []float64{512, 256, 256}, valid: true},
+		{name: "undershot_probabilities", values: []float64{0.5, 0.25}, valid: false},
+		{name: "undershot_counts", values: []float64{512, 256, 200}, valid: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t2 *testing.T) {
+			err := newTestResult([]string{"00", "01", "11"}, testCase.values).Validate(1024)
+			if testCase.valid && err != nil {
+				t2.Errorf("expected a valid result but got: %s", err)
+			}
+			if !testCase.valid && err == nil {
+				t2.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestExpResult_Histogram(t *testing.T) {
+	// Sample 5 qubit experiment result
+	var r jobExecResp
+	body := `{"result": {"data": {"p": {"qubits": [0, 1, 2, 3, 4], "labels": ["00000", "00011", "00101", "11111"], "values": [0.4990234375, 0.0302734375, 0.0126953125, 0.4580078125]}}}}`
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		t.Fatal(err)
+	}
+	result := r.expResult()
+
+	expectedHist := map[string]float64{"00000": 0.4990234375, "00011": 0.0302734375, "00101": 0.0126953125, "11111": 0.4580078125}
+	if hist := result.Histogram(); !reflect.DeepEqual(hist, expectedHist) {
+		t.Errorf("expected histogram %v but got %v", expectedHist, hist)
+	}
+
+	expectedCounts := map[string]int{"00000": 511, "00011": 31, "00101": 13, "11111": 469}
+	if counts := result.Counts(1024); !reflect.DeepEqual(counts, expectedCounts) {
+		t.Errorf("expected counts %v but got %v", expectedCounts, counts)
+	}
+
+	t.Run("mismatched", func(t2 *testing.T) {
+		result := newTestResult([]string{"00", "11"}, []float64{1})
+		if _, err := result.ParseHistogram(); err == nil {
+			t2.Error("expected an error for mismatched labels and values")
+		}
+		if result.Histogram() != nil || result.Counts(1024) != nil {
+			t2.Error("expected no histogram or counts for mismatched labels and values")
+		}
+	})
+}
+
+func TestExpResult_CountsExact(t *testing.T) {
+	testCases := []struct {
+		values []float64
+		shots int
+	}{
+		{values: []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, shots: 1024},
+		{values: []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, shots: 2},
+		{values: []float64{0.5, 0.5}, shots: 1},
+		{values: []float64{0.125, 0.125, 0.125, 0.125, 0.125, 0.125, 0.125, 0.125}, shots: 7},
+		{values: []float64{0.999, 0.0005, 0.0005}, shots: 100},
+		{values: []float64{0.1, 0.2, 0.3, 0.4000001}, shots: 8192},
+		{values: []float64{0.25, 0.25, 0.25}, shots: 1000},
+	}
+
+	for _, testCase := range testCases {
+		labels := make([]string, len(testCase.values))
+		for i := range labels {
+			labels[i] = string(rune('a' + i))
+		}
+
+		counts := newTestResult(labels, testCase.values).CountsExact(testCase.shots)
+		var sum int
+		for _, count := range counts {
+			if count < 0 {
+				t.Errorf("expected no negative counts for %v but got %v", testCase.values, counts)
+			}
+			sum += count
+		}
+		if sum != testCase.shots {
+			t.Errorf("expected counts of %v to sum to %d but got %d: %v", testCase.values, testCase.shots, sum, counts)
+		}
+	}
+
+	// Ties are broken by bitstring, so rounding is deterministic
+	expected := map[string]int{"00": 1, "01": 1, "10": 0}
+	if counts := newTestResult([]string{"10", "01", "00"}, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}).CountsExact(2); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected counts %v but got %v", expected, counts)
+	}
+
+	if newTestResult([]string{"00", "11"}, []float64{1}).CountsExact(1024) != nil {
+		t.Error("expected no counts for mismatched labels and values")
+	}
+}